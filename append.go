@@ -0,0 +1,192 @@
+package qrgo
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Most symbols a structured append sequence can hold.
+const maxAppend = 16
+
+// The structured append header in front of every symbol of a
+// sequence: the mode indicator, the 4-bit index of the symbol, the
+// 4-bit total number of symbols minus one and the parity byte.
+//
+//		Symbol 2 of 4, parity 0x5A:
+//			0011 0001 0011 01011010
+//
+func appendHeader(sequence, total int, parity byte) string {
+	return indAppend + padLeft(strconv.FormatInt(int64(sequence), 2), 4) +
+		padLeft(strconv.FormatInt(int64(total-1), 2), 4) +
+		padLeft(strconv.FormatInt(int64(parity), 2), 8)
+}
+
+// Number of bits of the structured append header, none for
+// standalone symbols.
+func appendBits(total int) int {
+	if total == 0 {
+		return 0
+	}
+	return 20
+}
+
+// The parity of a sequence, the XOR of all bytes of the data as
+// written to the symbols. Kanji mode chars count with the two bytes of
+// their Shift JIS code and GS separators as such.
+//
+//		点 -> 0x935F -> 0x93 ^ 0x5F = 0xCC
+//
+func dataParity(mode int, data string) byte {
+	parity := byte(0)
+	if mode == kanji {
+		for _, c := range data {
+			code, _ := toShiftJIS(c)
+			parity ^= byte(code>>8) ^ byte(code)
+		}
+		return parity
+	}
+	for i := 0; i < len(data); i++ {
+		parity ^= data[i]
+	}
+	return parity
+}
+
+// The parity of the data of the chosen segments.
+func (qr *QR) appendParity() byte {
+	parity := byte(0)
+	for _, s := range qr.segments {
+		data := s.data
+		if s.mode == alpha && qr.FNC1 != 0 {
+			data = unescapeFNC1(data)
+		}
+		parity ^= dataParity(s.mode, data)
+	}
+	return parity
+}
+
+// Encode data at the given error correction level into a structured
+// append sequence of up to 16 symbols of at most the given version.
+// The data is split between chars into as few symbols as possible,
+// each filled up to the capacity of that version. Data fitting into
+// a single symbol still gets a sequence of one.
+func NewQRAppend(data string, level, version int) ([]*QR, error) {
+	if len(data) == 0 {
+		return nil, errors.New("Empty data input.")
+	}
+	if level < LevelL || level > LevelH {
+		return nil, errors.New("Unknown error correction level.")
+	}
+	if version < 1 || version > versions {
+		return nil, errors.New("Unknown version.")
+	}
+
+	// Byte offsets of the chars.
+	offsets := []int{}
+	for i := range data {
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(data))
+
+	// All symbols share one character set. Every part is written in
+	// the one it needs first. If any part needs UTF-8, the data is
+	// split again with every part in UTF-8 behind the ECI header.
+	capacity := blockInfo[level][version][0] * 8
+	symbols := []*QR{}
+	for _, utf8 := range []bool{false, true} {
+		newPart := func(part string) *QR {
+			qr := &QR{Data: part, Level: level, fixedECI: utf8}
+			if utf8 {
+				qr.ECI = ECIUTF8
+			}
+			return qr
+		}
+		fits := func(part string) bool {
+			qr := newPart(part)
+			qr.Total = maxAppend
+			segments := qr.segmentText(version)
+			return appendBits(qr.Total)+eciBits(qr.ECI)+segmentBits(segments, version) <= capacity
+		}
+
+		parts := []string{}
+		for start := 0; start+1 < len(offsets); {
+			// The longest run of chars from start that still fits.
+			low, high := start, len(offsets)-1
+			for low < high {
+				mid := (low + high + 1) / 2
+				if fits(data[offsets[start]:offsets[mid]]) {
+					low = mid
+				} else {
+					high = mid - 1
+				}
+			}
+			if low == start {
+				return nil, errors.New("Data input too long.")
+			}
+			parts = append(parts, data[offsets[start]:offsets[low]])
+			if len(parts) > maxAppend {
+				return nil, errors.New("Data input too long for 16 symbols.")
+			}
+			start = low
+		}
+
+		symbols = []*QR{}
+		needsUTF8 := false
+		for i, part := range parts {
+			qr := newPart(part)
+			qr.Sequence, qr.Total = i, len(parts)
+			qr.version(qr.segmentText)
+			needsUTF8 = needsUTF8 || qr.ECI == ECIUTF8
+			symbols = append(symbols, qr)
+		}
+		if !needsUTF8 || utf8 {
+			break
+		}
+	}
+
+	// The parity covers all symbols, so their segments are chosen
+	// before any is built.
+	parity := byte(0)
+	for _, qr := range symbols {
+		parity ^= qr.appendParity()
+	}
+	for _, qr := range symbols {
+		qr.Parity = parity
+		if _, err := qr.build(); err != nil {
+			return nil, err
+		}
+	}
+	return symbols, nil
+}
+
+// Reassemble joins the data of the decoded symbols of a structured
+// append sequence in the order of their index. The symbols may be
+// given in any order, but all of them must be present. Repeated
+// symbols are ignored.
+func Reassemble(results []*Result) (string, error) {
+	if len(results) == 0 || results[0].Total == 0 {
+		return "", errors.New("Not a structured append sequence.")
+	}
+
+	total, parity := results[0].Total, results[0].Parity
+	parts := make([]*Result, total)
+	for _, res := range results {
+		if res.Total != total || res.Parity != parity || res.Sequence >= total {
+			return "", errors.New("Symbols of different sequences.")
+		}
+		parts[res.Sequence] = res
+	}
+
+	data, check := "", byte(0)
+	for i, res := range parts {
+		if res == nil {
+			return "", errors.New("Missing symbol " + strconv.Itoa(i+1) +
+				" of " + strconv.Itoa(total) + ".")
+		}
+		data += res.Data
+		check ^= res.parity
+	}
+	if check != parity {
+		return "", errors.New("Parity of the sequence does not match.")
+	}
+	return data, nil
+}
@@ -0,0 +1,144 @@
+package qrgo
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendHeader(t *testing.T) {
+	// Doc example
+	assert.Equal(t, "0011"+"0001"+"0011"+"01011010", appendHeader(1, 4, 0x5a))
+	assert.Equal(t, "0011"+"1111"+"1111"+"00000000", appendHeader(15, 16, 0))
+	assert.Equal(t, 0, appendBits(0))
+	assert.Equal(t, 20, appendBits(3))
+	assert.Equal(t, byte('A'^'B'^'C'), dataParity(alpha, "ABC"))
+	// Doc example
+	assert.Equal(t, byte(0xcc), dataParity(kanji, "点"))
+	assert.Equal(t, byte(0x93^0x5f^0xe4^0xaa), dataParity(kanji, "点茗"))
+}
+
+func TestNewQRAppend(t *testing.T) {
+	data := strings.Repeat("SHIPPING DOCUMENT 4711 Lieferschein für Zürich ", 12)
+	symbols, err := NewQRAppend(data, LevelM, 5)
+	assert.Nil(t, err)
+	assert.True(t, len(symbols) > 1)
+
+	// Written in ISO-8859-1 without ECI.
+	latin1 := toLatin1(data)
+	parity := byte(0)
+	for i := 0; i < len(latin1); i++ {
+		parity ^= latin1[i]
+	}
+	results := []*Result{}
+	joined := ""
+	for i, qr := range symbols {
+		assert.Equal(t, i, qr.Sequence)
+		assert.Equal(t, len(symbols), qr.Total)
+		assert.Equal(t, parity, qr.Parity)
+		assert.True(t, qr.Version <= 5)
+		joined += qr.Data
+
+		res, err := Decode(qr.Matrix())
+		assert.Nil(t, err)
+		assert.Equal(t, 0, res.ECI)
+		assert.Equal(t, qr.Data, res.Data)
+		assert.Equal(t, i, res.Sequence)
+		results = append([]*Result{res}, results...)
+	}
+	assert.Equal(t, data, joined)
+
+	// All but the last symbol are filled up.
+	for _, qr := range symbols[:len(symbols)-1] {
+		assert.Equal(t, 5, qr.Version)
+	}
+
+	reassembled, err := Reassemble(results)
+	assert.Nil(t, err)
+	assert.Equal(t, data, reassembled)
+
+	// Kanji mode data counts in Shift JIS.
+	data = strings.Repeat("点茗", 41)
+	symbols, err = NewQRAppend(data, LevelL, 2)
+	assert.Nil(t, err)
+	assert.True(t, len(symbols) > 1)
+	results = []*Result{}
+	for _, qr := range symbols {
+		assert.Equal(t, kanji, qr.Mode)
+		assert.Equal(t, byte(0x93^0x5f^0xe4^0xaa), qr.Parity)
+		res, _ := Decode(qr.Matrix())
+		results = append(results, res)
+	}
+	reassembled, err = Reassemble(results)
+	assert.Nil(t, err)
+	assert.Equal(t, data, reassembled)
+
+	// One part beyond ISO-8859-1 puts all of them into UTF-8.
+	data = strings.Repeat("Zürich ", 30) + "Ελλάδα"
+	symbols, err = NewQRAppend(data, LevelL, 3)
+	assert.Nil(t, err)
+	assert.True(t, len(symbols) > 1)
+	results = []*Result{}
+	for _, qr := range symbols {
+		assert.Equal(t, ECIUTF8, qr.ECI)
+		res, _ := Decode(qr.Matrix())
+		results = append(results, res)
+	}
+	reassembled, err = Reassemble(results)
+	assert.Nil(t, err)
+	assert.Equal(t, data, reassembled)
+
+	// Short data still forms a sequence.
+	symbols, err = NewQRAppend("HELLO", LevelL, 40)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(symbols))
+	assert.Equal(t, 1, symbols[0].Total)
+	assert.Equal(t, 1, symbols[0].Version)
+}
+
+func TestNewQRAppendErrors(t *testing.T) {
+	_, err := NewQRAppend("", LevelL, 1)
+	assert.NotNil(t, err)
+	_, err = NewQRAppend("DATA", 4, 1)
+	assert.NotNil(t, err)
+	_, err = NewQRAppend("DATA", LevelL, 41)
+	assert.NotNil(t, err)
+	_, err = NewQRAppend(strings.Repeat("a", 16*15+1), LevelL, 1)
+	assert.NotNil(t, err)
+
+	symbols, err := NewQRAppend(strings.Repeat("a", 16*15), LevelL, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 16, len(symbols))
+}
+
+func TestReassemble(t *testing.T) {
+	symbols, _ := NewQRAppend(strings.Repeat("0123456789", 30), LevelH, 2)
+	results := []*Result{}
+	for _, qr := range symbols {
+		res, _ := Decode(qr.Matrix())
+		results = append(results, res)
+	}
+
+	_, err := Reassemble(results[1:])
+	assert.EqualError(t, err, "Missing symbol 1 of "+strconv.Itoa(len(symbols))+".")
+	_, err = Reassemble(nil)
+	assert.NotNil(t, err)
+
+	single, _ := NewQR("STANDALONE")
+	res, _ := Decode(single.Matrix())
+	_, err = Reassemble([]*Result{res})
+	assert.NotNil(t, err)
+
+	other, _ := NewQRAppend("OTHER SEQUENCE", LevelL, 1)
+	res, _ = Decode(other[0].Matrix())
+	_, err = Reassemble(append(results, res))
+	assert.NotNil(t, err)
+
+	corrupt := *results[0]
+	corrupt.Data = "X" + corrupt.Data[1:]
+	corrupt.parity ^= '0' ^ 'X'
+	_, err = Reassemble(append([]*Result{&corrupt}, results[1:]...))
+	assert.NotNil(t, err)
+}
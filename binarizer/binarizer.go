@@ -0,0 +1,197 @@
+// Package binarizer turns grayscale images into dark and light pixels
+// for the QR reader. Rows of the result are indexed by y, then x, and
+// dark pixels are true.
+package binarizer
+
+import (
+	"image"
+	"image/color"
+)
+
+const (
+	// Side length in pixels of the blocks of the local threshold.
+	blockSize = 8
+
+	// Blocks whose luminance varies less than this are treated
+	// as uniform, as their own average would only amplify noise.
+	minDynamicRange = 24
+)
+
+// Luminance of every pixel of the image.
+func luminance(img image.Image) [][]uint8 {
+	bounds := img.Bounds()
+	gray := make([][]uint8, bounds.Dy())
+	for y := range gray {
+		gray[y] = make([]uint8, bounds.Dx())
+		for x := range gray[y] {
+			c := color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y))
+			gray[y][x] = c.(color.Gray).Y
+		}
+	}
+	return gray
+}
+
+// Global binarizes the image with a single threshold chosen from the
+// histogram of its luminance. The threshold maximises the variance
+// between the dark and the light class (Otsu's method), which works
+// well for evenly lit images with a clear separation of the two.
+func Global(img image.Image) [][]bool {
+	gray := luminance(img)
+
+	var histogram [256]int
+	total := 0
+	for _, row := range gray {
+		for _, lum := range row {
+			histogram[lum]++
+			total++
+		}
+	}
+	threshold := otsu(histogram, total)
+
+	bits := make([][]bool, len(gray))
+	for y, row := range gray {
+		bits[y] = make([]bool, len(row))
+		for x, lum := range row {
+			bits[y][x] = int(lum) <= threshold
+		}
+	}
+	return bits
+}
+
+// The luminance up to which pixels count as dark.
+func otsu(histogram [256]int, total int) int {
+	sum := 0
+	for lum, n := range histogram {
+		sum += lum * n
+	}
+
+	threshold, best := 0, -1.0
+	dark, darkSum := 0, 0
+	for lum, n := range histogram {
+		dark += n
+		darkSum += lum * n
+		light := total - dark
+		if dark == 0 || light == 0 {
+			continue
+		}
+		darkMean := float64(darkSum) / float64(dark)
+		lightMean := float64(sum-darkSum) / float64(light)
+		variance := float64(dark) * float64(light) * (lightMean - darkMean) * (lightMean - darkMean)
+		if variance > best {
+			threshold, best = lum, variance
+		}
+	}
+	return threshold
+}
+
+// Local binarizes the image with a threshold adapted to the
+// neighbourhood of every pixel, which copes with shadows and light
+// gradients across the image.
+//
+// The image is divided into blocks of 8x8 pixels. Every pixel of a
+// block is compared against the average luminance of the 5x5 blocks
+// around it. Blocks without contrast, such as the inside of a large
+// module or plain background, take over the dark level of their
+// neighbours or else count as light.
+func Local(img image.Image) [][]bool {
+	gray := luminance(img)
+	height := len(gray)
+	if height == 0 {
+		return [][]bool{}
+	}
+	width := len(gray[0])
+	rows, cols := (height+blockSize-1)/blockSize, (width+blockSize-1)/blockSize
+
+	averages := make([][]int, rows)
+	for i := range averages {
+		averages[i] = make([]int, cols)
+		for j := range averages[i] {
+			sum, count, low, high := 0, 0, 255, 0
+			for y := i * blockSize; y < min(height, (i+1)*blockSize); y++ {
+				for x := j * blockSize; x < min(width, (j+1)*blockSize); x++ {
+					lum := int(gray[y][x])
+					sum += lum
+					count++
+					low = min(low, lum)
+					high = max(high, lum)
+				}
+			}
+
+			avg := sum / count
+			if high-low <= minDynamicRange {
+				// Assume a light block unless the neighbours
+				// already seen say it is darker than that.
+				avg = low / 2
+				if i > 0 && j > 0 {
+					neighbours := (averages[i-1][j] + 2*averages[i][j-1] + averages[i-1][j-1]) / 4
+					if low < neighbours {
+						avg = neighbours
+					}
+				}
+			}
+			averages[i][j] = avg
+		}
+	}
+
+	bits := make([][]bool, height)
+	for y := range bits {
+		bits[y] = make([]bool, width)
+	}
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			sum, count := 0, 0
+			for di := -2; di <= 2; di++ {
+				for dj := -2; dj <= 2; dj++ {
+					r, c := clamp(i+di, rows), clamp(j+dj, cols)
+					sum += averages[r][c]
+					count++
+				}
+			}
+			threshold := sum / count
+
+			for y := i * blockSize; y < min(height, (i+1)*blockSize); y++ {
+				for x := j * blockSize; x < min(width, (j+1)*blockSize); x++ {
+					bits[y][x] = int(gray[y][x]) <= threshold
+				}
+			}
+		}
+	}
+	return bits
+}
+
+// Invert swaps dark and light pixels, so that symbols printed light
+// on dark match the dark on light convention of the encoder.
+func Invert(bits [][]bool) [][]bool {
+	inverted := make([][]bool, len(bits))
+	for y, row := range bits {
+		inverted[y] = make([]bool, len(row))
+		for x, dark := range row {
+			inverted[y][x] = !dark
+		}
+	}
+	return inverted
+}
+
+func clamp(i, n int) int {
+	if i < 0 {
+		return 0
+	}
+	if i >= n {
+		return n - 1
+	}
+	return i
+}
+
+func min(a, b int) int {
+	if a <= b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a >= b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,69 @@
+package binarizer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOtsu(t *testing.T) {
+	var histogram [256]int
+	histogram[30] = 10
+	histogram[40] = 5
+	histogram[200] = 20
+	histogram[220] = 5
+	threshold := otsu(histogram, 40)
+	assert.True(t, threshold >= 40 && threshold < 200)
+}
+
+func TestGlobal(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 4, 1))
+	img.SetGray(0, 0, color.Gray{20})
+	img.SetGray(1, 0, color.Gray{90})
+	img.SetGray(2, 0, color.Gray{180})
+	img.SetGray(3, 0, color.Gray{250})
+	assert.Equal(t, [][]bool{{true, true, false, false}}, Global(img))
+}
+
+func TestLocal(t *testing.T) {
+	// Stripes whose light parts on the right are darker than
+	// the dark parts on the left.
+	img := image.NewGray(image.Rect(0, 0, 96, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 96; x++ {
+			base := 220 - x
+			if (x/4)%2 == 0 {
+				base -= 50
+			}
+			img.SetGray(x, y, color.Gray{uint8(base)})
+		}
+	}
+
+	bits := Local(img)
+	for y := 16; y < 48; y++ {
+		for x := 16; x < 80; x++ {
+			assert.Equal(t, (x/4)%2 == 0, bits[y][x], x, y)
+		}
+	}
+	assert.NotEqual(t, bits, Global(img))
+}
+
+func TestLocalUniform(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 20, 20))
+	for i := range img.Pix {
+		img.Pix[i] = 230
+	}
+	for _, row := range Local(img) {
+		for _, dark := range row {
+			assert.False(t, dark)
+		}
+	}
+	assert.Equal(t, [][]bool{}, Local(image.NewGray(image.Rect(0, 0, 0, 0))))
+}
+
+func TestInvert(t *testing.T) {
+	assert.Equal(t, [][]bool{{false, true}, {true, true}},
+		Invert([][]bool{{true, false}, {false, false}}))
+}
@@ -0,0 +1,430 @@
+package qrgo
+
+import (
+	"errors"
+	"strconv"
+)
+
+// The payload and parameters read back from a symbol.
+type Result struct {
+	Data    string
+	Bytes   []byte // Byte mode data as read, e.g. binary payloads.
+	Mode    int
+	ECI     int // Designator of the last ECI header, 0 for none.
+	Version int
+	Mask    int
+	Level   int
+
+	// Position in a structured append sequence, see Reassemble.
+	Sequence int
+	Total    int
+	Parity   byte
+	parity   byte // Parity of the data of this symbol.
+
+	// FNC1 position and application indicator. GS separators
+	// appear as such in the data.
+	FNC1        int
+	Application int
+}
+
+// Characters of the alphanumeric mode ordered by their value
+// in the alphaTable.
+const alphaChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// Number of differing characters between two binary strings
+// of the same length.
+func hamming(a, b string) int {
+	dist := 0
+	for i := 0; i < len(a); i++ {
+		if a[i] != b[i] {
+			dist++
+		}
+	}
+	return dist
+}
+
+// Reads the modules at the given coordinates into a binary string.
+func readBits(matrix [][]bool, coords [][2]int) string {
+	bits := ""
+	for _, rc := range coords {
+		if matrix[rc[0]][rc[1]] {
+			bits += "1"
+		} else {
+			bits += "0"
+		}
+	}
+	return bits
+}
+
+// Coordinates of the two copies of the format information, most
+// significant bit first. The first copy surrounds the top-left
+// finder pattern, the second one is split between the other two.
+func formatCoordinates(modules int) (first, second [][2]int) {
+	for i := 14; i >= 0; i-- {
+		switch {
+		case i <= 5:
+			first = append(first, [2]int{i, 8})
+		case i <= 7:
+			first = append(first, [2]int{i + 1, 8})
+		case i == 8:
+			first = append(first, [2]int{8, 7})
+		default:
+			first = append(first, [2]int{8, 14 - i})
+		}
+
+		if i <= 7 {
+			second = append(second, [2]int{8, modules - 1 - i})
+		} else {
+			second = append(second, [2]int{modules - 15 + i, 8})
+		}
+	}
+	return first, second
+}
+
+// Coordinates of the two copies of the version information, most
+// significant bit first.
+func versionCoordinates(modules int) (first, second [][2]int) {
+	for i := 17; i >= 0; i-- {
+		a, b := modules-11+i%3, i/3
+		first = append(first, [2]int{b, a})
+		second = append(second, [2]int{a, b})
+	}
+	return first, second
+}
+
+// The format information is corrected by picking the closest of
+// the 32 valid code words. BCH(15,5) tolerates up to 3 bit errors.
+func readFormatInformation(matrix [][]bool) (level, mask int, err error) {
+	first, second := formatCoordinates(len(matrix))
+	copies := []string{readBits(matrix, first), readBits(matrix, second)}
+
+	best := 4
+	for l := LevelL; l <= LevelH; l++ {
+		for m := 0; m < len(masks); m++ {
+			fis := formatInformation(l, m)
+			for _, read := range copies {
+				if dist := hamming(fis, read); dist < best {
+					level, mask, best = l, m, dist
+				}
+			}
+		}
+	}
+	if best > 3 {
+		return 0, 0, errors.New("Unreadable format information.")
+	}
+	return level, mask, nil
+}
+
+// The version information is corrected the same way as the format
+// information, among the code words of versions 7 to 40.
+func readVersionInformation(matrix [][]bool) (int, error) {
+	first, second := versionCoordinates(len(matrix))
+	copies := []string{readBits(matrix, first), readBits(matrix, second)}
+
+	version, best := 0, 4
+	for v := 7; v <= versions; v++ {
+		vis := versionInformation(v)
+		for _, read := range copies {
+			if dist := hamming(vis, read); dist < best {
+				version, best = v, dist
+			}
+		}
+	}
+	if best > 3 {
+		return 0, errors.New("Unreadable version information.")
+	}
+	return version, nil
+}
+
+// Inverse of interleaveData and interleaveError. Splits the
+// codewords back into blocks of data words followed by their
+// error correction words.
+func deinterleave(array []byte, numErr, numB1, numB2, numW1, numW2 int) [][]byte {
+	blocks := make([][]byte, numB1+numB2)
+	for i := range blocks {
+		if i < numB1 {
+			blocks[i] = make([]byte, 0, numW1+numErr)
+		} else {
+			blocks[i] = make([]byte, 0, numW2+numErr)
+		}
+	}
+
+	x := 0
+	for i := 0; i < max(numW1, numW2); i++ {
+		for j := range blocks {
+			if j < numB1 && i >= numW1 || j >= numB1 && i >= numW2 {
+				continue
+			}
+			blocks[j] = append(blocks[j], array[x])
+			x++
+		}
+	}
+	for i := 0; i < numErr; i++ {
+		for j := range blocks {
+			blocks[j] = append(blocks[j], array[x])
+			x++
+		}
+	}
+	return blocks
+}
+
+// Parses the mode segments of the error corrected data bits into
+// the result. Reading stops at the terminator or when the bits run
+// out. Byte mode data is converted from the character set given by
+// the ECI to UTF-8.
+func parseSegments(bits string, res *Result) error {
+	for pos := 0; pos+4 <= len(bits); {
+		ind := bits[pos : pos+4]
+		pos += 4
+
+		mode := 0
+		switch ind {
+		case "0000":
+			return nil
+		case indNumeric:
+			mode = numeric
+		case indAlpha:
+			mode = alpha
+		case indBytes:
+			mode = byteMode
+		case indKanji:
+			mode = kanji
+		case indAppend:
+			if pos+16 > len(bits) {
+				return errors.New("Truncated structured append header.")
+			}
+			sequence, _ := strconv.ParseInt(bits[pos:pos+4], 2, 64)
+			total, _ := strconv.ParseInt(bits[pos+4:pos+8], 2, 64)
+			parity, _ := strconv.ParseInt(bits[pos+8:pos+16], 2, 64)
+			res.Sequence, res.Total, res.Parity = int(sequence), int(total)+1, byte(parity)
+			pos += 16
+			continue
+		case indFNC1:
+			res.FNC1 = FNC1First
+			continue
+		case indAIM:
+			if pos+8 > len(bits) {
+				return errors.New("Truncated application indicator.")
+			}
+			application, _ := strconv.ParseInt(bits[pos:pos+8], 2, 64)
+			res.FNC1, res.Application = FNC1Second, int(application)
+			pos += 8
+			continue
+		case indECI:
+			designator, n, err := readECI(bits[pos:])
+			if err != nil {
+				return err
+			}
+			res.ECI = designator
+			pos += n
+			continue
+		default:
+			return errors.New("Unsupported mode indicator " + ind + ".")
+		}
+		if res.Mode == 0 {
+			res.Mode = mode
+		}
+
+		width := countBits(mode, res.Version)
+		if pos+width > len(bits) {
+			return errors.New("Truncated count indicator.")
+		}
+		count, _ := strconv.ParseInt(bits[pos:pos+width], 2, 64)
+		pos += width
+
+		segment, n, err := decSegment(bits[pos:], mode, int(count))
+		if err != nil {
+			return err
+		}
+		if mode == alpha && res.FNC1 != 0 {
+			segment = unescapeFNC1(segment)
+		}
+		res.parity ^= dataParity(mode, segment)
+		if mode == byteMode {
+			res.Bytes = append(res.Bytes, segment...)
+			segment = fromCharset([]byte(segment), res.ECI)
+		}
+		res.Data += segment
+		pos += n
+	}
+	return nil
+}
+
+// Inverse of eciHeader without the mode indicator. Reports the
+// designator and the number of bits consumed.
+func readECI(bits string) (int, int, error) {
+	size := 8
+	switch {
+	case len(bits) > 0 && bits[0] == '0':
+	case len(bits) > 1 && bits[:2] == "10":
+		size = 16
+	case len(bits) > 2 && bits[:3] == "110":
+		size = 24
+	default:
+		return 0, 0, errors.New("Invalid ECI designator.")
+	}
+	if size > len(bits) {
+		return 0, 0, errors.New("Truncated ECI designator.")
+	}
+	designator, _ := strconv.ParseInt(bits[size/8:size], 2, 64)
+	return int(designator), size, nil
+}
+
+// Decodes count characters of the given mode from the start of bits
+// and reports how many bits were consumed.
+func decSegment(bits string, mode, count int) (string, int, error) {
+	var size int
+	switch mode {
+	case numeric:
+		size = count/3*10 + []int{0, 4, 7}[count%3]
+	case alpha:
+		size = count/2*11 + count%2*6
+	case kanji:
+		size = count * 13
+	default:
+		size = count * 8
+	}
+	if size > len(bits) {
+		return "", 0, errors.New("Truncated data segment.")
+	}
+
+	switch mode {
+	case numeric:
+		return decNumeric(bits[:size], count), size, nil
+	case alpha:
+		data, err := decAlpha(bits[:size], count)
+		return data, size, err
+	case kanji:
+		data, err := decKanji(bits[:size])
+		return data, size, err
+	default:
+		return string(encodingToByteArray(bits[:size])), size, nil
+	}
+}
+
+// Inverse of encNumeric.
+func decNumeric(bits string, count int) string {
+	data := ""
+	for pos := 0; count > 0; {
+		digits, width := 3, 10
+		if count < 3 {
+			digits, width = count, []int{0, 4, 7}[count]
+		}
+		num, _ := strconv.ParseInt(bits[pos:pos+width], 2, 64)
+		data += padLeft(strconv.FormatInt(num, 10), digits)
+		pos += width
+		count -= digits
+	}
+	return data
+}
+
+// Inverse of encAlpha.
+func decAlpha(bits string, count int) (string, error) {
+	data := ""
+	for pos := 0; count > 0; {
+		if count == 1 {
+			num, _ := strconv.ParseInt(bits[pos:pos+6], 2, 64)
+			if num >= 45 {
+				return "", errors.New("Invalid alphanumeric character.")
+			}
+			return data + alphaChars[num:num+1], nil
+		}
+		num, _ := strconv.ParseInt(bits[pos:pos+11], 2, 64)
+		if num >= 45*45 {
+			return "", errors.New("Invalid alphanumeric character.")
+		}
+		data += alphaChars[num/45:num/45+1] + alphaChars[num%45:num%45+1]
+		pos += 11
+		count -= 2
+	}
+	return data, nil
+}
+
+// Inverse of encKanji.
+func decKanji(bits string) (string, error) {
+	data := ""
+	for pos := 0; pos < len(bits); pos += 13 {
+		num, _ := strconv.ParseInt(bits[pos:pos+13], 2, 64)
+		code := int(num/0xc0<<8 | num%0xc0)
+		if code < 0x1f00 {
+			code += 0x8140
+		} else {
+			code += 0xc140
+		}
+		c, ok := fromShiftJIS(code)
+		if !ok {
+			return "", errors.New("Invalid Kanji character.")
+		}
+		data += string(c)
+	}
+	return data, nil
+}
+
+// Decode reads a symbol from its module matrix, indexed by row
+// and column with true for dark modules. The quiet zone must not
+// be part of the matrix.
+//
+// The format and version information are read and corrected first.
+// The mask is then removed from the data modules, which are read in
+// placement order, split into blocks and corrected using Reed-Solomon
+// before the mode segments are parsed.
+func Decode(matrix [][]bool) (*Result, error) {
+	modules := len(matrix)
+	if modules < 21 || modules > 177 || (modules-17)%4 != 0 {
+		return nil, errors.New("Invalid matrix size.")
+	}
+	for _, row := range matrix {
+		if len(row) != modules {
+			return nil, errors.New("Matrix is not square.")
+		}
+	}
+
+	res := Result{Version: (modules - 17) / 4}
+	if res.Version >= 7 {
+		version, err := readVersionInformation(matrix)
+		if err != nil {
+			return nil, err
+		}
+		if version != res.Version {
+			return nil, errors.New("Version information does not match matrix size.")
+		}
+	}
+
+	var err error
+	res.Level, res.Mask, err = readFormatInformation(matrix)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove the mask while reading the data modules.
+	qr := QR{Version: res.Version, Modules: modules}
+	qr.drawFunctionPatterns()
+	bits := ""
+	walkDataModules(qr.Canvas, 6, func(r, c int) {
+		if matrix[r][c] != masks[res.Mask](r, c) {
+			bits += "1"
+		} else {
+			bits += "0"
+		}
+	})
+
+	info := blockInfo[res.Level][res.Version]
+	numErr, numB1, numW1, numB2, numW2 := info[1], info[2], info[3], info[4], info[5]
+	codewords := encodingToByteArray(bits[:len(bits)/8*8])
+	blocks := deinterleave(codewords, numErr, numB1, numB2, numW1, numW2)
+
+	dec := NewRSDecoder(NewField(0x11d, 2), numErr)
+	var data []byte
+	for _, block := range blocks {
+		words, _, err := dec.Decode(block)
+		if err != nil {
+			return nil, errors.New("Too many errors in data codewords.")
+		}
+		data = append(data, words...)
+	}
+
+	if err := parseSegments(byteArrayToEncoding(data), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
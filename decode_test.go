@@ -0,0 +1,112 @@
+package qrgo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeinterleave(t *testing.T) {
+	// Two groups of two blocks with four and five data words
+	// and two error correction words each.
+	inter := []byte{1, 5, 9, 14, 2, 6, 10, 15, 3, 7, 11, 16, 4, 8, 12, 17, 13, 18,
+		21, 23, 25, 27, 22, 24, 26, 28}
+	blocks := deinterleave(inter, 2, 2, 2, 4, 5)
+	assert.Equal(t, [][]byte{
+		{1, 2, 3, 4, 21, 22},
+		{5, 6, 7, 8, 23, 24},
+		{9, 10, 11, 12, 13, 25, 26},
+		{14, 15, 16, 17, 18, 27, 28}}, blocks)
+}
+
+func TestDecNumeric(t *testing.T) {
+	assert.Equal(t, "8675309", decNumeric(encNumeric("8675309"), 7))
+	assert.Equal(t, "00201", decNumeric(encNumeric("00201"), 5))
+	assert.Equal(t, "0", decNumeric(encNumeric("0"), 1))
+}
+
+func TestDecAlpha(t *testing.T) {
+	data, err := decAlpha(encAlpha("HELLO WORLD"), 11)
+	assert.Nil(t, err)
+	assert.Equal(t, "HELLO WORLD", data)
+
+	_, err = decAlpha("111111", 1)
+	assert.NotNil(t, err)
+}
+
+func TestDecKanji(t *testing.T) {
+	data, err := decKanji(encKanji("点茗漢字"))
+	assert.Nil(t, err)
+	assert.Equal(t, "点茗漢字", data)
+
+	// 0x8240 is not assigned.
+	_, err = decKanji("0000011000000")
+	assert.NotNil(t, err)
+}
+
+func TestDecodeSymbols(t *testing.T) {
+	inputs := []string{
+		"0", "01234567", "HELLO WORLD", "EPFLLAUSANNE2016SWITZERLAND",
+		"日本語の商品名", strings.Repeat("東京都千代田区", 30),
+		"Grüße aus Zürich", "Ελληνικά 😀 ok",
+		"Hello, world!", strings.Repeat("1234567890", 30),
+		strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20),
+	}
+	for level := LevelL; level <= LevelH; level++ {
+		for _, data := range inputs {
+			qr, err := NewQRLevel(data, level)
+			assert.Nil(t, err)
+
+			res, err := Decode(qr.Matrix())
+			assert.Nil(t, err)
+			assert.Equal(t, data, res.Data)
+			assert.Equal(t, qr.Mode, res.Mode)
+			assert.Equal(t, qr.Version, res.Version)
+			assert.Equal(t, qr.Mask, res.Mask)
+			assert.Equal(t, level, res.Level)
+		}
+	}
+}
+
+func TestDecodeBinary(t *testing.T) {
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	qr, _ := NewQRBytes(data)
+	res, err := Decode(qr.Matrix())
+	assert.Nil(t, err)
+	assert.Equal(t, data, res.Bytes)
+}
+
+func TestDecodeDamaged(t *testing.T) {
+	qr, _ := NewQRLevel(strings.Repeat("DAMAGED LABEL ", 10), LevelH)
+	matrix := qr.Matrix()
+
+	// Flip a block of modules in the lower right data region and
+	// one bit of each copy of the format information.
+	for r := qr.Modules - 12; r < qr.Modules-2; r++ {
+		for c := qr.Modules - 12; c < qr.Modules-2; c++ {
+			matrix[r][c] = !matrix[r][c]
+		}
+	}
+	matrix[8][0] = !matrix[8][0]
+	matrix[8][qr.Modules-1] = !matrix[8][qr.Modules-1]
+
+	res, err := Decode(matrix)
+	assert.Nil(t, err)
+	assert.Equal(t, qr.Data, res.Data)
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	_, err := Decode(make([][]bool, 20))
+	assert.NotNil(t, err)
+
+	matrix := make([][]bool, 21)
+	for i := range matrix {
+		matrix[i] = make([]bool, 21)
+	}
+	_, err = Decode(matrix)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,141 @@
+package qrgo
+
+import (
+	"errors"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Common ECI designators. Designators 3 to 13 select the parts of
+// ISO/IEC 8859, e.g. 4 for Latin-2 and 9 for Greek.
+const (
+	ECILatin1 = 3
+	ECIUTF8   = 26
+
+	// Writes byte mode text in ISO-8859-1 without ECI header, see
+	// Options.
+	NoECI = -1
+
+	maxECI = 999999
+)
+
+// The ECI mode indicator followed by the designator, which takes
+// one, two or three bytes depending on its value.
+//
+//		[0, 127]:			0xxxxxxx
+//		[128, 16383]:		10xxxxxx xxxxxxxx
+//		[16384, 999999]:	110xxxxx xxxxxxxx xxxxxxxx
+//
+func eciHeader(designator int) string {
+	value := strconv.FormatInt(int64(designator), 2)
+	switch {
+	case designator < 1<<7:
+		return indECI + padLeft(value, 8)
+	case designator < 1<<14:
+		return indECI + "10" + padLeft(value, 14)
+	default:
+		return indECI + "110" + padLeft(value, 21)
+	}
+}
+
+// Number of bits of the ECI header, none without a designator.
+func eciBits(designator int) int {
+	if designator == 0 {
+		return 0
+	}
+	return len(eciHeader(designator))
+}
+
+// Reports whether the data is UTF-8 text of ISO-8859-1 chars only.
+func isLatin1(data string) bool {
+	if !utf8.ValidString(data) {
+		return false
+	}
+	for _, c := range data {
+		if c > 0xff {
+			return false
+		}
+	}
+	return true
+}
+
+// Scanners read byte mode as ISO-8859-1 unless told otherwise. Byte
+// mode segments of text within ISO-8859-1 are converted to it and need
+// no ECI. If one holds text beyond it, all of them stay UTF-8 and the
+// UTF-8 ECI is returned. Invalid UTF-8 is binary data, kept as is.
+//
+//		Zürich:	5A FC 72 69 63 68, no ECI
+//		Ελλάδα:	CE 95 CE BB CE BB ..., ECI 26
+//
+func byteCharset(segments []segment) int {
+	if beyondLatin1(segments) {
+		return ECIUTF8
+	}
+	latin1Segments(segments)
+	return 0
+}
+
+// Reports whether a byte mode segment holds text beyond ISO-8859-1.
+func beyondLatin1(segments []segment) bool {
+	for _, s := range segments {
+		if s.mode == byteMode && utf8.ValidString(s.data) && !isLatin1(s.data) {
+			return true
+		}
+	}
+	return false
+}
+
+// Converts the byte mode segments of text within ISO-8859-1 to it.
+func latin1Segments(segments []segment) {
+	for i, s := range segments {
+		if s.mode == byteMode && isLatin1(s.data) {
+			segments[i].data = toLatin1(s.data)
+		}
+	}
+}
+
+// Converts UTF-8 text of ISO-8859-1 chars to ISO-8859-1.
+func toLatin1(data string) string {
+	latin1 := make([]byte, 0, len(data))
+	for _, c := range data {
+		latin1 = append(latin1, byte(c))
+	}
+	return string(latin1)
+}
+
+// Converts byte mode data read in the character set of the ECI
+// designator to UTF-8. Without ECI it is ISO-8859-1. Data in other
+// character sets than ISO-8859-1 and UTF-8 is returned as is.
+func fromCharset(data []byte, designator int) string {
+	switch designator {
+	case 0, 1, ECILatin1:
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	default:
+		return string(data)
+	}
+}
+
+// Encode data in the character set given by the ECI designator at
+// the lowest error correction level L. The data is written as a single
+// byte mode segment behind the ECI header, e.g. ISO-8859-7 text with
+// designator 9. NewQR chooses ECIUTF8 by itself when needed, and
+// NewQRWithOptions writes text in ISO-8859-1 or UTF-8 at any level.
+// Decode returns such data as is, see Result.Bytes.
+func NewQRECI(data []byte, designator int) (*QR, error) {
+	if len(data) == 0 {
+		return nil, errors.New("Empty data input.")
+	}
+	if designator < 1 || designator > maxECI {
+		return nil, errors.New("Invalid ECI designator.")
+	}
+
+	qr := QR{Data: string(data), Level: LevelL, ECI: designator}
+	qr.version(func(int) []segment {
+		return []segment{{byteMode, qr.Data}}
+	})
+	return qr.build()
+}
@@ -0,0 +1,110 @@
+package qrgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestECIHeader(t *testing.T) {
+	assert.Equal(t, "0111"+"00000011", eciHeader(ECILatin1))
+	assert.Equal(t, "0111"+"00011010", eciHeader(ECIUTF8))
+	assert.Equal(t, "0111"+"10"+"00000010000000", eciHeader(128))
+	assert.Equal(t, "0111"+"110"+"011110100001000111111", eciHeader(maxECI))
+	assert.Equal(t, 0, eciBits(0))
+	assert.Equal(t, 28, eciBits(16384))
+
+	for _, designator := range []int{1, 26, 127, 128, 16383, 16384, maxECI} {
+		header := eciHeader(designator)
+		value, n, err := readECI(header[4:] + "0000")
+		assert.Nil(t, err)
+		assert.Equal(t, designator, value)
+		assert.Equal(t, len(header)-4, n)
+	}
+	_, _, err := readECI("1110")
+	assert.NotNil(t, err)
+	_, _, err = readECI("10000")
+	assert.NotNil(t, err)
+}
+
+func TestAutomaticECI(t *testing.T) {
+	qr, _ := NewQR("plain ASCII")
+	assert.Equal(t, 0, qr.ECI)
+
+	// Kanji mode needs no ECI.
+	qr, _ = NewQR("漢字")
+	assert.Equal(t, 0, qr.ECI)
+
+	qr, _ = NewQR("Ελληνικά")
+	assert.Equal(t, ECIUTF8, qr.ECI)
+
+	// Latin-1 text is written in it.
+	qr, _ = NewQR("Zürich")
+	assert.Equal(t, 0, qr.ECI)
+	assert.Equal(t, 6, qr.Length)
+
+	// Binary data is not text.
+	qr, _ = NewQRBytes([]byte("Ελληνικά"))
+	assert.Equal(t, 0, qr.ECI)
+
+	// The header counts towards the capacity.
+	data := "€" + string(make([]byte, 14))
+	qr, _ = NewQR(data)
+	assert.Equal(t, 2, qr.Version)
+}
+
+func TestByteCharset(t *testing.T) {
+	// Doc example
+	segments := []segment{{byteMode, "Zürich"}}
+	assert.Equal(t, 0, byteCharset(segments))
+	assert.Equal(t, "\x5a\xfc\x72\x69\x63\x68", segments[0].data)
+
+	segments = []segment{{byteMode, "Zürich "}, {kanji, "漢字"}, {byteMode, "Ελλάδα"}}
+	assert.Equal(t, ECIUTF8, byteCharset(segments))
+	assert.Equal(t, "Zürich ", segments[0].data)
+
+	segments = []segment{{byteMode, "\xff\xfe"}, {numeric, "123"}}
+	assert.Equal(t, 0, byteCharset(segments))
+	assert.Equal(t, "\xff\xfe", segments[0].data)
+}
+
+func TestFromCharset(t *testing.T) {
+	assert.Equal(t, "Zürich", fromCharset([]byte("Z\xfcrich"), 0))
+	assert.Equal(t, "Zürich", fromCharset([]byte("Z\xfcrich"), ECILatin1))
+	assert.Equal(t, "Zürich", fromCharset([]byte("Zürich"), ECIUTF8))
+	assert.Equal(t, "\xc5\xeb", fromCharset([]byte{0xc5, 0xeb}, 9))
+}
+
+func TestNewQRECI(t *testing.T) {
+	// "Ελλάδα" in ISO-8859-7
+	greek := []byte{0xc5, 0xeb, 0xeb, 0xdc, 0xe4, 0xe1}
+	qr, err := NewQRECI(greek, 9)
+	assert.Nil(t, err)
+	assert.Equal(t, 9, qr.ECI)
+
+	res, err := Decode(qr.Matrix())
+	assert.Nil(t, err)
+	assert.Equal(t, 9, res.ECI)
+	assert.Equal(t, byteMode, res.Mode)
+	assert.Equal(t, greek, []byte(res.Data))
+	assert.Equal(t, greek, res.Bytes)
+
+	// Both character sets read back as UTF-8.
+	qr, _ = NewQR("Zürich")
+	res, _ = Decode(qr.Matrix())
+	assert.Equal(t, 0, res.ECI)
+	assert.Equal(t, "Zürich", res.Data)
+	assert.Equal(t, []byte("Z\xfcrich"), res.Bytes)
+
+	qr, _ = NewQR("Ελλάδα")
+	res, _ = Decode(qr.Matrix())
+	assert.Equal(t, ECIUTF8, res.ECI)
+	assert.Equal(t, "Ελλάδα", res.Data)
+
+	_, err = NewQRECI(greek, 0)
+	assert.NotNil(t, err)
+	_, err = NewQRECI(greek, maxECI+1)
+	assert.NotNil(t, err)
+	_, err = NewQRECI(nil, ECIUTF8)
+	assert.NotNil(t, err)
+}
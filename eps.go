@@ -0,0 +1,71 @@
+package qrgo
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Options of WriteEPS.
+type EPSOptions struct {
+	// Size of a module in points, e.g. 0.5 * Millimetre. 0 draws
+	// every module 1 mm wide.
+	ModuleSize float64
+
+	// Width of the quiet zone in modules, as in ImageOptions.
+	QuietZone int
+}
+
+// Writes the symbol as Encapsulated PostScript. The bounding box
+// covers the symbol and its quiet zone. Every row is drawn as runs of
+// dark modules, each one a single call of the procedure R.
+//
+//		Top row of a 21x21 symbol without quiet zone:
+//			0 20 7 R 9 20 1 R 11 20 2 R 14 20 7 R
+//
+func (qr *QR) WriteEPS(w io.Writer, opts EPSOptions) error {
+	if len(qr.Canvas) == 0 {
+		return errors.New("Symbol without modules.")
+	}
+	quiet := qr.quietZone(opts.QuietZone)
+	module := opts.ModuleSize
+	if module <= 0 {
+		module = Millimetre
+	}
+	rows := len(qr.Canvas)
+	width := float64(len(qr.Canvas[0])+2*quiet) * module
+	height := float64(rows+2*quiet) * module
+
+	out := bufio.NewWriter(w)
+	out.WriteString("%!PS-Adobe-3.0 EPSF-3.0\n" +
+		"%%BoundingBox: 0 0 " + strconv.Itoa(int(math.Ceil(width))) + " " +
+		strconv.Itoa(int(math.Ceil(height))) + "\n" +
+		"%%HiResBoundingBox: 0 0 " + pdfNumber(width) + " " + pdfNumber(height) + "\n" +
+		"%%Creator: qrgo\n%%LanguageLevel: 2\n%%Pages: 1\n%%EndComments\n" +
+		"gsave\n/R { 1 rectfill } bind def\n0 setgray\n" +
+		pdfNumber(module) + " dup scale\n")
+	for r, row := range qr.Canvas {
+		line := ""
+		for c := 0; c < len(row); c++ {
+			if row[c].color == 0 {
+				continue
+			}
+			start := c
+			for c+1 < len(row) && row[c+1].color == 1 {
+				c++
+			}
+			if line != "" {
+				line += " "
+			}
+			line += strconv.Itoa(start+quiet) + " " + strconv.Itoa(rows-1-r+quiet) + " " +
+				strconv.Itoa(c-start+1) + " R"
+		}
+		if line != "" {
+			out.WriteString(line + "\n")
+		}
+	}
+	out.WriteString("grestore\nshowpage\n%%EOF\n")
+	return out.Flush()
+}
@@ -0,0 +1,51 @@
+package qrgo
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteEPS(t *testing.T) {
+	qr, _ := NewQR("EPS")
+	var buf bytes.Buffer
+	assert.Nil(t, qr.WriteEPS(&buf, EPSOptions{QuietZone: -1}))
+	eps := buf.String()
+	assert.True(t, strings.HasPrefix(eps, "%!PS-Adobe-3.0 EPSF-3.0\n%%BoundingBox: 0 0 60 60\n"+
+		"%%HiResBoundingBox: 0 0 59.52756 59.52756\n"))
+	assert.True(t, strings.HasSuffix(eps, "grestore\nshowpage\n%%EOF\n"))
+	// Doc example
+	assert.Regexp(t, "\n0 20 7 R( \\d+ 20 \\d+ R)* 14 20 7 R\n", eps)
+
+	// Redraw the runs and compare with the symbol.
+	matrix := make([][]bool, 21)
+	for r := range matrix {
+		matrix[r] = make([]bool, 21)
+	}
+	for _, line := range strings.Split(eps, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[len(fields)-1] != "R" || len(fields)%4 != 0 {
+			continue
+		}
+		for i := 0; i < len(fields); i += 4 {
+			x, _ := strconv.Atoi(fields[i])
+			y, _ := strconv.Atoi(fields[i+1])
+			n, _ := strconv.Atoi(fields[i+2])
+			for c := x; c < x+n; c++ {
+				matrix[20-y][c] = true
+			}
+		}
+	}
+	assert.Equal(t, qr.Matrix(), matrix)
+
+	buf.Reset()
+	rmqr, _ := NewRMQRHeight("EPS", LevelM, 7)
+	assert.Nil(t, rmqr.WriteEPS(&buf, EPSOptions{ModuleSize: 2}))
+	assert.Contains(t, buf.String(), "%%BoundingBox: 0 0 94 22\n")
+	assert.Contains(t, buf.String(), "\n2 dup scale\n2 8 7 R ")
+
+	assert.NotNil(t, (&QR{}).WriteEPS(&buf, EPSOptions{}))
+}
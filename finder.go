@@ -0,0 +1,339 @@
+package qrgo
+
+import (
+	"math"
+	"sort"
+)
+
+// A finder pattern located in an image. Coordinates are
+// in pixels, with pixel (x, y) covering [x, x+1) x [y, y+1).
+type finderPattern struct {
+	x, y   float64
+	module float64 // Estimated module size in pixels.
+	count  int     // Number of scans confirming the pattern.
+}
+
+// A run of equally coloured pixels along a row.
+type run struct {
+	dark          bool
+	start, length int
+}
+
+func runLengths(row []bool) []run {
+	runs := []run{}
+	for x, dark := range row {
+		if len(runs) > 0 && runs[len(runs)-1].dark == dark {
+			runs[len(runs)-1].length++
+		} else {
+			runs = append(runs, run{dark, x, 1})
+		}
+	}
+	return runs
+}
+
+// Reports whether the five runs crossing a finder pattern follow
+// its 1:1:3:1:1 ratio. Each run may be off by half a module.
+//
+//		1111111
+//		1000001
+//		1011101	-> 1:1:3:1:1
+//		1011101
+//		1011101
+//		1000001
+//		1111111
+//
+func finderRatio(counts [5]int) bool {
+	total := 0
+	for _, c := range counts {
+		if c == 0 {
+			return false
+		}
+		total += c
+	}
+	if total < 7 {
+		return false
+	}
+
+	module := float64(total) / 7
+	variance := module / 2
+	return math.Abs(module-float64(counts[0])) < variance &&
+		math.Abs(module-float64(counts[1])) < variance &&
+		math.Abs(3*module-float64(counts[2])) < 3*variance &&
+		math.Abs(module-float64(counts[3])) < variance &&
+		math.Abs(module-float64(counts[4])) < variance
+}
+
+// Counts the five runs of a finder pattern crossing the dark pixel
+// (x, y) along the axis given by (dx, dy) and returns the centre of
+// the middle run on that axis together with the total length.
+func crossCheck(bits [][]bool, x, y, dx, dy int) (float64, float64, int, bool) {
+	height, width := len(bits), len(bits[0])
+	inside := func(k int) bool {
+		px, py := x+k*dx, y+k*dy
+		return px >= 0 && py >= 0 && px < width && py < height
+	}
+	dark := func(k int) bool {
+		return bits[y+k*dy][x+k*dx]
+	}
+	if !inside(0) || !dark(0) {
+		return 0, 0, 0, false
+	}
+
+	var counts [5]int
+	k := 0
+	for state := 2; state >= 0; state-- {
+		for inside(k) && dark(k) == (state%2 == 0) {
+			counts[state]++
+			k--
+		}
+	}
+	k = 1
+	for state := 2; state <= 4; state++ {
+		for inside(k) && dark(k) == (state%2 == 0) {
+			counts[state]++
+			k++
+		}
+	}
+	if !finderRatio(counts) {
+		return 0, 0, 0, false
+	}
+
+	end := float64(k - counts[4] - counts[3])
+	centre := end - float64(counts[2])/2
+	cx := float64(x) + float64(dx)*centre + float64(1-dx)*0.5
+	cy := float64(y) + float64(dy)*centre + float64(1-dy)*0.5
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	return cx, cy, total, true
+}
+
+// Scans every row of the image for runs in finder pattern ratio and
+// confirms them by crossing the middle run vertically and once more
+// horizontally through the refined centre. Hits close to each other
+// are merged into a single pattern.
+func findFinderPatterns(bits [][]bool) []*finderPattern {
+	patterns := []*finderPattern{}
+	for y, row := range bits {
+		runs := runLengths(row)
+		for i := 0; i+4 < len(runs); i++ {
+			if !runs[i].dark {
+				continue
+			}
+			var counts [5]int
+			total := 0
+			for j := range counts {
+				counts[j] = runs[i+j].length
+				total += counts[j]
+			}
+			if !finderRatio(counts) {
+				continue
+			}
+
+			x := runs[i+2].start + runs[i+2].length/2
+			_, cy, vertical, ok := crossCheck(bits, x, y, 0, 1)
+			if !ok || 5*abs(vertical-total) >= 2*total {
+				continue
+			}
+			cx, _, horizontal, ok := crossCheck(bits, x, int(cy), 1, 0)
+			if !ok || 5*abs(horizontal-total) >= 2*total {
+				continue
+			}
+			patterns = addFinderPattern(patterns, cx, cy, float64(vertical+horizontal)/14)
+		}
+	}
+	return patterns
+}
+
+// Merges a hit into a known pattern of similar size nearby,
+// or appends it as a new one.
+func addFinderPattern(patterns []*finderPattern, x, y, module float64) []*finderPattern {
+	for _, p := range patterns {
+		if math.Abs(x-p.x) <= p.module && math.Abs(y-p.y) <= p.module &&
+			math.Abs(module-p.module) <= math.Max(1, p.module/2) {
+			n := float64(p.count)
+			p.x = (p.x*n + x) / (n + 1)
+			p.y = (p.y*n + y) / (n + 1)
+			p.module = (p.module*n + module) / (n + 1)
+			p.count++
+			return patterns
+		}
+	}
+	return append(patterns, &finderPattern{x, y, module, 1})
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func distance(a, b *finderPattern) float64 {
+	return math.Hypot(a.x-b.x, a.y-b.y)
+}
+
+// How far three patterns are from the right isosceles triangle
+// spanned by the finder patterns of a symbol. Zero is a perfect fit.
+func triangleError(a, b, c *finderPattern) float64 {
+	sides := []float64{distance(a, b), distance(b, c), distance(a, c)}
+	sort.Float64s(sides)
+	modules := []float64{a.module, b.module, c.module}
+	sort.Float64s(modules)
+	if sides[0] == 0 || modules[2] > 2*modules[0] {
+		return math.Inf(1)
+	}
+	hyp := sides[0]*sides[0] + sides[1]*sides[1]
+	return (sides[1]-sides[0])/sides[1] + math.Abs(sides[2]*sides[2]-hyp)/hyp
+}
+
+// Orders three finder patterns as top-left, top-right and
+// bottom-left. The top-left one is opposite the longest side and
+// the other two follow clockwise in image coordinates.
+func orderFinderPatterns(a, b, c *finderPattern) (tl, tr, bl *finderPattern) {
+	ab, bc, ac := distance(a, b), distance(b, c), distance(a, c)
+	if bc >= ab && bc >= ac {
+		tl, tr, bl = a, b, c
+	} else if ac >= ab && ac >= bc {
+		tl, tr, bl = b, a, c
+	} else {
+		tl, tr, bl = c, a, b
+	}
+	cross := (tr.x-tl.x)*(bl.y-tl.y) - (tr.y-tl.y)*(bl.x-tl.x)
+	if cross < 0 {
+		tr, bl = bl, tr
+	}
+	return tl, tr, bl
+}
+
+// Groups up to limit of the most confirmed patterns into triples that
+// could form the corners of a symbol, ordered from the best fit to the
+// worst. Each triple is ordered as top-left, top-right and bottom-left.
+func finderTriples(patterns []*finderPattern, limit int) [][3]*finderPattern {
+	sorted := append([]*finderPattern{}, patterns...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].count > sorted[j].count
+	})
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+
+	triples, errs := [][3]*finderPattern{}, []float64{}
+	for i := 0; i < len(sorted); i++ {
+		for j := i + 1; j < len(sorted); j++ {
+			for k := j + 1; k < len(sorted); k++ {
+				e := triangleError(sorted[i], sorted[j], sorted[k])
+				if math.IsInf(e, 1) {
+					continue
+				}
+				tl, tr, bl := orderFinderPatterns(sorted[i], sorted[j], sorted[k])
+				triples = append(triples, [3]*finderPattern{tl, tr, bl})
+				errs = append(errs, e)
+			}
+		}
+	}
+	sort.Sort(byError{triples, errs})
+	return triples
+}
+
+// Sorts triples of finder patterns by their triangle error.
+type byError struct {
+	triples [][3]*finderPattern
+	errs    []float64
+}
+
+func (b byError) Len() int           { return len(b.triples) }
+func (b byError) Less(i, j int) bool { return b.errs[i] < b.errs[j] }
+func (b byError) Swap(i, j int) {
+	b.triples[i], b.triples[j] = b.triples[j], b.triples[i]
+	b.errs[i], b.errs[j] = b.errs[j], b.errs[i]
+}
+
+// Reports whether a run is within tolerance of the expected size.
+func nearSize(length int, size float64) bool {
+	return math.Abs(float64(length)-size) < size*0.6
+}
+
+// Crosses the dark centre of an alignment pattern at (x, y) along the
+// axis given by (dx, dy). The centre must be about one module wide and
+// be enclosed by light runs of the same width, followed by dark ones.
+// Returns the centre of the dark run on that axis.
+func crossCheckAlignment(bits [][]bool, x, y, dx, dy int, module float64) (float64, bool) {
+	height, width := len(bits), len(bits[0])
+	inside := func(k int) bool {
+		px, py := x+k*dx, y+k*dy
+		return px >= 0 && py >= 0 && px < width && py < height
+	}
+	dark := func(k int) bool {
+		return bits[y+k*dy][x+k*dx]
+	}
+	if !inside(0) || !dark(0) {
+		return 0, false
+	}
+
+	back, forth := 0, 1
+	for inside(back-1) && dark(back-1) {
+		back--
+	}
+	for inside(forth) && dark(forth) {
+		forth++
+	}
+	before, after := back-1, forth
+	for inside(before) && !dark(before) {
+		before--
+	}
+	for inside(after) && !dark(after) {
+		after++
+	}
+	if !inside(before) || !inside(after) || !nearSize(forth-back, module) ||
+		!nearSize(back-1-before, module) || !nearSize(after-forth, module) {
+		return 0, false
+	}
+	return float64(back+forth) / 2, true
+}
+
+// Searches the square of the given radius around (x, y) for an
+// alignment pattern and returns the centre of the one closest
+// to (x, y).
+//
+//		11111
+//		10001
+//		10101	-> 1:1:1 around the centre
+//		10001
+//		11111
+//
+func findAlignmentPattern(bits [][]bool, x, y, module, radius float64) (float64, float64, bool) {
+	height, width := len(bits), len(bits[0])
+	x0, x1 := int(math.Max(0, x-radius)), int(math.Min(float64(width), x+radius))
+	y0, y1 := int(math.Max(0, y-radius)), int(math.Min(float64(height), y+radius))
+
+	best, bx, by := math.Inf(1), 0.0, 0.0
+	for row := y0; row < y1; row++ {
+		if x0 >= x1 {
+			break
+		}
+		runs := runLengths(bits[row][x0:x1])
+		for i := 1; i+1 < len(runs); i++ {
+			if !runs[i].dark || !nearSize(runs[i].length, module) ||
+				!nearSize(runs[i-1].length, module) || !nearSize(runs[i+1].length, module) {
+				continue
+			}
+			cx := x0 + runs[i].start + runs[i].length/2
+			offset, ok := crossCheckAlignment(bits, cx, row, 0, 1, module)
+			if !ok {
+				continue
+			}
+			cy := float64(row) + offset
+			offset, ok = crossCheckAlignment(bits, cx, int(cy), 1, 0, module)
+			if !ok {
+				continue
+			}
+			px := float64(cx) + offset
+			if d := math.Hypot(px-x, cy-y); d < best {
+				best, bx, by = d, px, cy
+			}
+		}
+	}
+	return bx, by, !math.IsInf(best, 1)
+}
@@ -0,0 +1,73 @@
+package qrgo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jeffallen/qrgo/binarizer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunLengths(t *testing.T) {
+	assert.Equal(t, []run{}, runLengths(nil))
+	assert.Equal(t, []run{{false, 0, 2}, {true, 2, 3}, {false, 5, 1}},
+		runLengths([]bool{false, false, true, true, true, false}))
+}
+
+func TestFinderRatio(t *testing.T) {
+	assert.True(t, finderRatio([5]int{1, 1, 3, 1, 1}))
+	assert.True(t, finderRatio([5]int{4, 4, 12, 4, 4}))
+	assert.True(t, finderRatio([5]int{5, 3, 13, 4, 4}))
+	assert.False(t, finderRatio([5]int{1, 1, 1, 1, 1}))
+	assert.False(t, finderRatio([5]int{4, 4, 4, 4, 12}))
+	assert.False(t, finderRatio([5]int{0, 1, 3, 1, 1}))
+}
+
+func TestFindFinderPatterns(t *testing.T) {
+	qr, _ := NewQR("HELLO WORLD")
+	img := renderSymbol(qr.Matrix(), 4, 0, 4*(qr.Modules+8))
+	patterns := findFinderPatterns(binarizer.Global(img))
+	triples := finderTriples(patterns, maxPatterns)
+	assert.Equal(t, 3, len(patterns))
+	assert.Equal(t, 1, len(triples))
+
+	tl, tr, bl := triples[0][0], triples[0][1], triples[0][2]
+	// Centres lie 3.5 modules into the symbol, after 4 modules
+	// of quiet zone.
+	assert.InDelta(t, 30, tl.x, 1)
+	assert.InDelta(t, 30, tl.y, 1)
+	assert.InDelta(t, 4*(4+21-3.5), tr.x, 1)
+	assert.InDelta(t, 30, tr.y, 1)
+	assert.InDelta(t, 30, bl.x, 1)
+	assert.InDelta(t, 4*(4+21-3.5), bl.y, 1)
+	assert.InDelta(t, 4, tl.module, 0.5)
+}
+
+func TestOrderFinderPatterns(t *testing.T) {
+	a := &finderPattern{x: 10, y: 10}
+	b := &finderPattern{x: 100, y: 10}
+	c := &finderPattern{x: 10, y: 100}
+	for _, perm := range [][3]*finderPattern{{a, b, c}, {b, c, a}, {c, a, b}, {c, b, a}} {
+		tl, tr, bl := orderFinderPatterns(perm[0], perm[1], perm[2])
+		assert.Equal(t, a, tl)
+		assert.Equal(t, b, tr)
+		assert.Equal(t, c, bl)
+	}
+}
+
+func TestFindAlignmentPattern(t *testing.T) {
+	qr, _ := NewQR(strings.Repeat("ALIGN", 10))
+	img := renderSymbol(qr.Matrix(), 4, 0, 4*(qr.Modules+8))
+	bits := binarizer.Global(img)
+
+	// The single alignment pattern of version 3 is centred on
+	// module 22, after 4 modules of quiet zone.
+	centre := 4 * (4 + 22 + 0.5)
+	x, y, ok := findAlignmentPattern(bits, centre+5, centre-3, 4, 16)
+	assert.True(t, ok)
+	assert.InDelta(t, centre, x, 0.5)
+	assert.InDelta(t, centre, y, 0.5)
+
+	_, _, ok = findAlignmentPattern(bits, 10, 10, 4, 8)
+	assert.False(t, ok)
+}
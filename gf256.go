@@ -5,7 +5,10 @@
 // Package gf256 implements arithmetic over the Galois Field GF(256).
 package qrgo
 
-import "strconv"
+import (
+	"errors"
+	"strconv"
+)
 
 // A Field represents an instance of GF(256) defined by a specific polynomial.
 type Field struct {
@@ -154,20 +157,32 @@ type RSEncoder struct {
 	p    []byte
 }
 
-func (f *Field) gen(e int) (gen, lgen []byte) {
+// roots returns the product of (x + r) for every r in rs,
+// most significant term first.
+func (f *Field) roots(rs []byte) []byte {
 	// p = 1
+	e := len(rs)
 	p := make([]byte, e+1)
 	p[e] = 1
 
-	for i := 0; i < e; i++ {
-		// p *= (x + Exp(i))
-		// p[j] = p[j]*Exp(i) + p[j+1].
-		c := f.Exp(i)
+	for _, c := range rs {
+		// p *= (x + c)
+		// p[j] = p[j]*c + p[j+1].
 		for j := 0; j < e; j++ {
 			p[j] = f.Mul(p[j], c) ^ p[j+1]
 		}
 		p[e] = f.Mul(p[e], c)
 	}
+	return p
+}
+
+func (f *Field) gen(e int) (gen, lgen []byte) {
+	// p = (x + Exp(0)) * ... * (x + Exp(e-1))
+	rs := make([]byte, e)
+	for i := range rs {
+		rs[i] = f.Exp(i)
+	}
+	p := f.roots(rs)
 
 	// lp = log p.
 	lp := make([]byte, e+1)
@@ -239,3 +254,181 @@ func (rs *RSEncoder) ECC(data []byte, check []byte) {
 	copy(check, p[len(data):])
 	rs.p = p
 }
+
+// An RSDecoder implements Reed-Solomon decoding
+// over a given field using a given number of error correction bytes.
+type RSDecoder struct {
+	f *Field
+	c int
+}
+
+// NewRSDecoder returns a new Reed-Solomon decoder
+// over the given field and number of error correction bytes.
+func NewRSDecoder(f *Field, c int) *RSDecoder {
+	return &RSDecoder{f: f, c: c}
+}
+
+var errTooManyErrors = errors.New("gf256: too many errors")
+
+// Decode corrects the received code word, made of data
+// followed by the check bytes, and returns the corrected data
+// along with the indexes into code of the bytes that were fixed.
+// If the errors cannot be corrected, Decode returns an error.
+func (rs *RSDecoder) Decode(code []byte) (data []byte, fixed []int, err error) {
+	return rs.DecodeErasures(code, nil)
+}
+
+// DecodeErasures is like Decode, but is also told the indexes
+// into code of bytes known to be unreadable. Each erasure costs
+// one check byte instead of the two needed to locate an error,
+// so up to 2*errors + erasures <= c bytes can be restored.
+func (rs *RSDecoder) DecodeErasures(code []byte, erasures []int) (data []byte, fixed []int, err error) {
+	if len(code) < rs.c || len(code) > 255 {
+		panic("gf256: invalid code word length")
+	}
+	f := rs.f
+	n := len(code)
+	if len(erasures) > rs.c {
+		return nil, nil, errTooManyErrors
+	}
+
+	synd, ok := rs.syndromes(code)
+	if ok {
+		return append([]byte(nil), code[:n-rs.c]...), nil, nil
+	}
+
+	// Polynomials below store the coefficient of x^i at index i,
+	// the reverse of the order used by the encoder. Reversing
+	// the product of (x + X) for every erased location X gives
+	// the erasure locator, the product of (1 + X*x).
+	xs := make([]byte, len(erasures))
+	for k, i := range erasures {
+		if i < 0 || i >= n {
+			panic("gf256: invalid erasure position")
+		}
+		xs[k] = f.Exp(n - 1 - i)
+	}
+	loc := rs.locator(synd, f.roots(xs))
+	nerr := len(loc) - 1
+	if nerr == 0 || 2*nerr-len(erasures) > rs.c {
+		return nil, nil, errTooManyErrors
+	}
+
+	// Chien search: the byte at index i has degree p = n-1-i
+	// and is in error iff loc(α^-p) == 0.
+	var found []int
+	for p := 0; p < n; p++ {
+		if polyEval(f, loc, f.Exp(255-p)) == 0 {
+			found = append(found, n-1-p)
+		}
+	}
+	if len(found) != nerr {
+		return nil, nil, errTooManyErrors
+	}
+
+	// Forney: the magnitude at location X is
+	// X * omega(X^-1) / loc'(X^-1), with omega = synd*loc mod x^c.
+	omega := make([]byte, rs.c)
+	for i, s := range synd {
+		for j := 0; j < len(loc) && i+j < rs.c; j++ {
+			omega[i+j] ^= f.Mul(s, loc[j])
+		}
+	}
+	dloc := make([]byte, len(loc)-1)
+	for i := 1; i < len(loc); i += 2 {
+		dloc[i-1] = loc[i]
+	}
+
+	out := append([]byte(nil), code...)
+	for _, i := range found {
+		x := f.Exp(n - 1 - i)
+		xinv := f.Inv(x)
+		den := polyEval(f, dloc, xinv)
+		if den == 0 {
+			return nil, nil, errTooManyErrors
+		}
+		// Erased bytes may have been read correctly after all.
+		if e := f.Mul(x, f.Mul(polyEval(f, omega, xinv), f.Inv(den))); e != 0 {
+			out[i] ^= e
+			fixed = append(fixed, i)
+		}
+	}
+	if _, ok := rs.syndromes(out); !ok {
+		return nil, nil, errTooManyErrors
+	}
+	return out[:n-rs.c], fixed, nil
+}
+
+// syndromes evaluates the received code word at the roots
+// α^0, ..., α^(c-1) of the generator polynomial.
+// It also reports whether all of them are zero.
+func (rs *RSDecoder) syndromes(code []byte) ([]byte, bool) {
+	f := rs.f
+	synd := make([]byte, rs.c)
+	ok := true
+	for j := range synd {
+		a := f.Exp(j)
+		var s byte
+		for _, b := range code {
+			s = f.Mul(s, a) ^ b
+		}
+		synd[j] = s
+		if s != 0 {
+			ok = false
+		}
+	}
+	return synd, ok
+}
+
+// locator runs Berlekamp-Massey on the syndromes, starting
+// from the erasure locator, and returns the combined locator
+// of errors and erasures, trimmed to its degree.
+func (rs *RSDecoder) locator(synd, erasures []byte) []byte {
+	f := rs.f
+	e := len(erasures) - 1
+	c := make([]byte, rs.c+1) // current locator
+	b := make([]byte, rs.c+1) // locator before the last length change
+	copy(c, erasures)
+	copy(b, erasures)
+	l, m, lastd := e, 1, byte(1)
+	for n := e; n < rs.c; n++ {
+		// d = discrepancy between synd[n] and its prediction.
+		d := synd[n]
+		for i := 1; i <= l; i++ {
+			d ^= f.Mul(c[i], synd[n-i])
+		}
+		if d == 0 {
+			m++
+			continue
+		}
+		coef := f.Mul(d, f.Inv(lastd))
+		t := append([]byte(nil), c...)
+		for i := 0; i+m < len(c); i++ {
+			c[i+m] ^= f.Mul(coef, b[i])
+		}
+		if 2*l <= n+e {
+			l = n + 1 + e - l
+			b = t
+			lastd = d
+			m = 1
+		} else {
+			m++
+		}
+	}
+	for i := l + 1; i < len(c); i++ {
+		if c[i] != 0 {
+			// The locator does not fit its length: uncorrectable.
+			return c[:1]
+		}
+	}
+	return c[:l+1]
+}
+
+// polyEval evaluates p, stored lowest degree first, at x.
+func polyEval(f *Field, p []byte, x byte) byte {
+	var y byte
+	for i := len(p) - 1; i >= 0; i-- {
+		y = f.Mul(y, x) ^ p[i]
+	}
+	return y
+}
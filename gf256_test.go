@@ -7,6 +7,8 @@ package qrgo
 import (
 	"bytes"
 	"fmt"
+	"math/rand"
+	"sort"
 	"testing"
 )
 
@@ -145,6 +147,104 @@ func TestGen(t *testing.T) {
 	}
 }
 
+func TestDecode(t *testing.T) {
+	data := []byte{0x10, 0x20, 0x0c, 0x56, 0x61, 0x80, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11}
+	check := []byte{0xa5, 0x24, 0xd4, 0xc1, 0xed, 0x36, 0xc7, 0x87, 0x2c, 0x55}
+	code := append(append([]byte(nil), data...), check...)
+	rs := NewRSDecoder(f, len(check))
+
+	out, fixed, err := rs.Decode(code)
+	if err != nil || !bytes.Equal(out, data) || len(fixed) != 0 {
+		t.Errorf("Decode(%x) = %x, %v, %v, want %x", code, out, fixed, err, data)
+	}
+
+	code[0] ^= 0xff
+	code[7] ^= 0x01
+	code[20] ^= 0x42
+	out, fixed, err = rs.Decode(code)
+	sort.Ints(fixed)
+	if err != nil || !bytes.Equal(out, data) || fmt.Sprint(fixed) != "[0 7 20]" {
+		t.Errorf("Decode(%x) = %x, %v, %v, want %x, [0 7 20]", code, out, fixed, err, data)
+	}
+	if code[0] != 0x10^0xff {
+		t.Errorf("Decode modified its input")
+	}
+}
+
+func TestDecodeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 1000; iter++ {
+		n := 2 + r.Intn(254)
+		c := 1 + r.Intn(n-1)
+		if c > 68 {
+			c = 68
+		}
+		code := make([]byte, n)
+		r.Read(code[:n-c])
+		NewRSEncoder(f, c).ECC(code[:n-c], code[n-c:])
+		want := append([]byte(nil), code[:n-c]...)
+
+		nerr := r.Intn(c/2 + 1)
+		for _, i := range r.Perm(n)[:nerr] {
+			code[i] ^= byte(1 + r.Intn(255))
+		}
+		out, fixed, err := NewRSDecoder(f, c).Decode(code)
+		if err != nil || !bytes.Equal(out, want) || len(fixed) != nerr {
+			t.Fatalf("n=%d c=%d: Decode with %d errors = %x, %v, %v, want %x", n, c, nerr, out, fixed, err, want)
+		}
+	}
+}
+
+func TestDecodeErasures(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for iter := 0; iter < 1000; iter++ {
+		n := 2 + r.Intn(254)
+		c := 1 + r.Intn(n-1)
+		if c > 68 {
+			c = 68
+		}
+		code := make([]byte, n)
+		r.Read(code[:n-c])
+		NewRSEncoder(f, c).ECC(code[:n-c], code[n-c:])
+		want := append([]byte(nil), code[:n-c]...)
+
+		nera := r.Intn(c + 1)
+		nerr := r.Intn((c-nera)/2 + 1)
+		perm := r.Perm(n)
+		erasures := perm[:nera]
+		for _, i := range perm[:nera+nerr] {
+			code[i] ^= byte(r.Intn(256))
+		}
+		out, _, err := NewRSDecoder(f, c).DecodeErasures(code, erasures)
+		if err != nil || !bytes.Equal(out, want) {
+			t.Fatalf("n=%d c=%d: DecodeErasures with %d erasures, %d errors = %x, %v, want %x", n, c, nera, nerr, out, err, want)
+		}
+	}
+
+	// Ten check bytes restore ten erasures but only five errors.
+	code := make([]byte, 26)
+	for i := 0; i < 10; i++ {
+		code[i] = byte(i + 1)
+	}
+	erasures := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	out, fixed, err := NewRSDecoder(f, 10).DecodeErasures(code, erasures)
+	if err != nil || !bytes.Equal(out, make([]byte, 16)) || len(fixed) != 10 {
+		t.Errorf("DecodeErasures(%x, %v) = %x, %v, %v", code, erasures, out, fixed, err)
+	}
+}
+
+func TestDecodeTooManyErrors(t *testing.T) {
+	code := make([]byte, 26)
+	for i := 0; i < 6; i++ {
+		code[i] = byte(i + 1)
+	}
+	// All zero is a code word; six errors are more than
+	// ten check bytes can correct.
+	if _, _, err := NewRSDecoder(f, 10).Decode(code); err == nil {
+		t.Errorf("Decode with 6 errors succeeded")
+	}
+}
+
 func TestReducible(t *testing.T) {
 	var count = []int{1, 2, 3, 6, 9, 18, 30, 56, 99, 186} // oeis.org/A1037
 	for i, want := range count {
@@ -0,0 +1,247 @@
+package qrgo
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Positions of the FNC1 mode indicator.
+const (
+	FNC1First  = 1 // GS1 element strings.
+	FNC1Second = 2 // Industry standards identified by an AIM application indicator.
+
+	// The group separator ending variable length element strings.
+	gs = '\x1d'
+)
+
+// An application identifier of the GS1 General Specifications. AIs
+// starting with prefix and of the given number of digits take
+// between min and max chars of data, all digits if numeric.
+type gs1AI struct {
+	prefix   string
+	digits   int
+	min, max int
+	numeric  bool
+}
+
+var (
+	gs1AIs = []gs1AI{
+		{"00", 2, 18, 18, true}, {"01", 2, 14, 14, true}, {"02", 2, 14, 14, true},
+		{"10", 2, 1, 20, false}, {"11", 2, 6, 6, true}, {"12", 2, 6, 6, true},
+		{"13", 2, 6, 6, true}, {"15", 2, 6, 6, true}, {"16", 2, 6, 6, true},
+		{"17", 2, 6, 6, true}, {"20", 2, 2, 2, true}, {"21", 2, 1, 20, false},
+		{"22", 2, 1, 20, false}, {"235", 3, 1, 28, false}, {"240", 3, 1, 30, false},
+		{"241", 3, 1, 30, false}, {"242", 3, 1, 6, true}, {"243", 3, 1, 20, false},
+		{"250", 3, 1, 30, false}, {"251", 3, 1, 30, false}, {"253", 3, 14, 30, false},
+		{"254", 3, 1, 20, false}, {"255", 3, 14, 25, true}, {"30", 2, 1, 8, true},
+		{"31", 4, 6, 6, true}, {"32", 4, 6, 6, true}, {"33", 4, 6, 6, true},
+		{"34", 4, 6, 6, true}, {"35", 4, 6, 6, true}, {"36", 4, 6, 6, true},
+		{"37", 2, 1, 8, true}, {"390", 4, 1, 15, true}, {"391", 4, 4, 18, true},
+		{"392", 4, 1, 15, true}, {"393", 4, 4, 18, true}, {"400", 3, 1, 30, false},
+		{"401", 3, 1, 30, false}, {"402", 3, 17, 17, true}, {"403", 3, 1, 30, false},
+		{"41", 3, 13, 13, true}, {"420", 3, 1, 20, false}, {"421", 3, 4, 12, false},
+		{"422", 3, 3, 3, true}, {"423", 3, 4, 15, true}, {"424", 3, 3, 3, true},
+		{"425", 3, 3, 15, true}, {"426", 3, 3, 3, true}, {"7001", 4, 13, 13, true},
+		{"7002", 4, 1, 30, false}, {"7003", 4, 10, 10, true}, {"7004", 4, 1, 4, true},
+		{"7006", 4, 6, 6, true}, {"7007", 4, 6, 12, true}, {"703", 4, 4, 30, false},
+		{"8001", 4, 14, 14, true}, {"8002", 4, 1, 20, false}, {"8003", 4, 15, 30, false},
+		{"8004", 4, 1, 30, false}, {"8005", 4, 6, 6, true}, {"8006", 4, 18, 18, true},
+		{"8007", 4, 1, 34, false}, {"8008", 4, 9, 12, true}, {"8017", 4, 18, 18, true},
+		{"8018", 4, 18, 18, true}, {"8019", 4, 1, 10, true}, {"8020", 4, 1, 25, false},
+		{"8200", 4, 1, 70, false}, {"90", 2, 1, 30, false}, {"91", 2, 1, 90, false},
+		{"92", 2, 1, 90, false}, {"93", 2, 1, 90, false}, {"94", 2, 1, 90, false},
+		{"95", 2, 1, 90, false}, {"96", 2, 1, 90, false}, {"97", 2, 1, 90, false},
+		{"98", 2, 1, 90, false}, {"99", 2, 1, 90, false},
+	}
+
+	// AIs starting with these digits have a predefined length and
+	// need no separator, even where their data is shorter.
+	gs1Predefined = []string{
+		"00", "01", "02", "03", "04", "11", "12", "13", "14", "15", "16",
+		"17", "18", "19", "20", "31", "32", "33", "34", "35", "36", "41"}
+
+	// AIs by prefix whose data starts with a number of the given
+	// digits ending in a check digit, like GTINs and SSCCs.
+	gs1CheckDigits = map[string]int{
+		"00": 18, "01": 14, "02": 14, "253": 13, "255": 13, "402": 17,
+		"41": 13, "8003": 14, "8006": 14, "8017": 18, "8018": 18,
+	}
+
+	// Chars allowed in the data of an element string.
+	gs1Chars = "!\"%&'()*+,-./0123456789:;<=>?ABCDEFGHIJKLMNOPQRSTUVWXYZ_abcdefghijklmnopqrstuvwxyz"
+)
+
+// The definition of an application identifier.
+func lookupAI(ai string) (gs1AI, bool) {
+	for _, def := range gs1AIs {
+		if len(ai) == def.digits && strings.HasPrefix(ai, def.prefix) {
+			return def, true
+		}
+	}
+	return gs1AI{}, false
+}
+
+// Reports whether the last of the digits is their GS1 check digit.
+// The other digits are weighted 3 and 1 alternately from the right,
+// and the check digit tops their sum up to a multiple of 10.
+//
+//		0950600013435 2:
+//			5*3 + 3 + 4*3 + 3 + 1*3 + 0 + 0 + 0 + 6*3 + 0 + 5*3 + 9 + 0 = 78
+//			78 + 2 = 80
+//
+func gs1CheckDigit(digits string) bool {
+	sum := 0
+	for i := len(digits) - 2; i >= 0; i-- {
+		weight := 1
+		if (len(digits)-2-i)%2 == 0 {
+			weight = 3
+		}
+		sum += int(digits[i]-'0') * weight
+	}
+	return (sum+int(digits[len(digits)-1]-'0'))%10 == 0
+}
+
+// ParseGS1 validates human readable GS1 element strings and converts
+// them into the data of a GS1 symbol. Every AI is given in brackets
+// and followed by its data, which therefore can't hold brackets
+// itself. Element strings of variable length are ended by a GS
+// separator unless they come last. Check digits of GTINs, SSCCs and
+// the like must be valid.
+//
+//		(01)09506000134352(10)ABC123(17)201225:
+//			01 09506000134352
+//			10 ABC123 GS
+//			17 201225
+//
+func ParseGS1(readable string) (string, error) {
+	if !strings.HasPrefix(readable, "(") {
+		return "", errors.New("GS1 data must start with an AI in brackets.")
+	}
+
+	data := ""
+	parts := strings.Split(readable[1:], "(")
+	for i, part := range parts {
+		end := strings.Index(part, ")")
+		if end < 0 {
+			return "", errors.New("Unterminated AI in GS1 data.")
+		}
+		ai, value := part[:end], part[end+1:]
+		def, ok := lookupAI(ai)
+		if !ok {
+			return "", errors.New("Unknown GS1 AI " + ai + ".")
+		}
+		if len(value) < def.min || len(value) > def.max {
+			return "", errors.New("Invalid length of GS1 AI " + ai + ".")
+		}
+		for _, c := range value {
+			if def.numeric && (c < '0' || c > '9') || !strings.ContainsRune(gs1Chars, c) {
+				return "", errors.New("Invalid char in GS1 AI " + ai + ".")
+			}
+		}
+		if n, ok := gs1CheckDigits[def.prefix]; ok && !gs1CheckDigit(value[:n]) {
+			return "", errors.New("Invalid check digit in GS1 AI " + ai + ".")
+		}
+
+		data += ai + value
+		predefined := false
+		for _, p := range gs1Predefined {
+			predefined = predefined || strings.HasPrefix(ai, p)
+		}
+		if !predefined && i < len(parts)-1 {
+			data += string(gs)
+		}
+	}
+	return data, nil
+}
+
+// The FNC1 mode indicator, followed by the application indicator
+// in the second position.
+func fnc1Header(position, application int) string {
+	if position == FNC1First {
+		return indFNC1
+	}
+	return indAIM + padLeft(strconv.FormatInt(int64(application), 2), 8)
+}
+
+// Number of bits of the FNC1 header, none without FNC1.
+func fnc1Bits(position int) int {
+	switch position {
+	case FNC1First:
+		return 4
+	case FNC1Second:
+		return 12
+	default:
+		return 0
+	}
+}
+
+// In alphanumeric segments following FNC1, a % stands for the GS
+// separator and literal ones are doubled.
+//
+//		10ABC%1 GS 17201225 -> 10ABC%%1%17201225
+//
+func escapeFNC1(data string) string {
+	return strings.NewReplacer("%", "%%", string(gs), "%").Replace(data)
+}
+
+// Inverse of escapeFNC1.
+func unescapeFNC1(data string) string {
+	unescaped := ""
+	for i := 0; i < len(data); i++ {
+		switch {
+		case data[i] != '%':
+			unescaped += data[i : i+1]
+		case i+1 < len(data) && data[i+1] == '%':
+			unescaped += "%"
+			i++
+		default:
+			unescaped += string(gs)
+		}
+	}
+	return unescaped
+}
+
+// Encode GS1 element strings given in human readable form, such as
+// (01)09506000134352(17)201225, at the given error correction level.
+// The data is validated by ParseGS1 and marked by FNC1 in the first
+// position.
+func NewQRGS1(readable string, level int) (*QR, error) {
+	data, err := ParseGS1(readable)
+	if err != nil {
+		return nil, err
+	}
+	return newQRFNC1(data, level, FNC1First, 0)
+}
+
+// Encode data following the industry standard given by its AIM
+// application indicator, either a single letter or two digits, at
+// the given error correction level. The data is marked by FNC1 in
+// the second position.
+func NewQRAIM(data string, level int, indicator string) (*QR, error) {
+	application := 0
+	switch {
+	case len(indicator) == 1 && (indicator[0] >= 'a' && indicator[0] <= 'z' ||
+		indicator[0] >= 'A' && indicator[0] <= 'Z'):
+		application = int(indicator[0]) + 100
+	case len(indicator) == 2 && indicator[0] >= '0' && indicator[0] <= '9' &&
+		indicator[1] >= '0' && indicator[1] <= '9':
+		application, _ = strconv.Atoi(indicator)
+	default:
+		return nil, errors.New("Invalid application indicator.")
+	}
+	return newQRFNC1(data, level, FNC1Second, application)
+}
+
+func newQRFNC1(data string, level, position, application int) (*QR, error) {
+	if len(data) == 0 {
+		return nil, errors.New("Empty data input.")
+	}
+	if level < LevelL || level > LevelH {
+		return nil, errors.New("Unknown error correction level.")
+	}
+
+	qr := QR{Data: data, Level: level, FNC1: position, Application: application}
+	qr.version(qr.segmentText)
+	return qr.build()
+}
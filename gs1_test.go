@@ -0,0 +1,118 @@
+package qrgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGS1(t *testing.T) {
+	data, err := ParseGS1("(01)09506000134352(17)201225")
+	assert.Nil(t, err)
+	assert.Equal(t, "0109506000134352"+"17201225", data)
+
+	// Doc example
+	data, err = ParseGS1("(01)09506000134352(10)ABC123(17)201225")
+	assert.Nil(t, err)
+	assert.Equal(t, "0109506000134352"+"10ABC123\x1d"+"17201225", data)
+
+	// Check digits
+	_, err = ParseGS1("(00)106141411234567897(414)9501101020917(8003)03870585000552987")
+	assert.Nil(t, err)
+
+	// No separator after the last element string.
+	data, err = ParseGS1("(3103)000189(21)12345")
+	assert.Nil(t, err)
+	assert.Equal(t, "3103000189"+"2112345", data)
+
+	invalid := []string{
+		"", "01)09506000134352", "(01", "(01)0950600013435", "(01)0950600013435A",
+		"(10)", "(10)ABC~", "(15)2012251", "(05)123", "(310)000189",
+		"(01)09506000134353", "(00)106141411234567896", "(414)9501101020910",
+	}
+	for _, readable := range invalid {
+		_, err := ParseGS1(readable)
+		assert.NotNil(t, err, readable)
+	}
+}
+
+func TestGS1CheckDigit(t *testing.T) {
+	// Doc example
+	assert.True(t, gs1CheckDigit("09506000134352"))
+	assert.False(t, gs1CheckDigit("09506000134353"))
+	assert.True(t, gs1CheckDigit("0"))
+	assert.True(t, gs1CheckDigit("48"))
+	assert.True(t, gs1CheckDigit("106141411234567897"))
+}
+
+func TestEscapeFNC1(t *testing.T) {
+	// Doc example
+	assert.Equal(t, "10ABC%%1%17201225", escapeFNC1("10ABC%1\x1d17201225"))
+	assert.Equal(t, "10ABC%1\x1d17201225", unescapeFNC1("10ABC%%1%17201225"))
+	assert.Equal(t, "%\x1d", unescapeFNC1(escapeFNC1("%\x1d")))
+}
+
+func TestFNC1Header(t *testing.T) {
+	assert.Equal(t, "0101", fnc1Header(FNC1First, 0))
+	assert.Equal(t, "1001"+"00110111", fnc1Header(FNC1Second, 55))
+	assert.Equal(t, 0, fnc1Bits(0))
+	assert.Equal(t, 4, fnc1Bits(FNC1First))
+	assert.Equal(t, 12, fnc1Bits(FNC1Second))
+}
+
+func TestNewQRGS1(t *testing.T) {
+	qr, err := NewQRGS1("(01)09506000134352(17)201225", LevelM)
+	assert.Nil(t, err)
+	assert.Equal(t, FNC1First, qr.FNC1)
+	assert.Equal(t, []segment{{numeric, "010950600013435217201225"}}, qr.segments)
+	assert.Equal(t, "0101"+"0001", byteArrayToEncoding(qr.Encoding)[:8])
+
+	res, err := Decode(qr.Matrix())
+	assert.Nil(t, err)
+	assert.Equal(t, FNC1First, res.FNC1)
+	assert.Equal(t, "010950600013435217201225", res.Data)
+
+	readable := "(01)09506000134352(10)ABC%123(21)xyz(17)201225"
+	qr, err = NewQRGS1(readable, LevelM)
+	assert.Nil(t, err)
+	data, _ := ParseGS1(readable)
+	res, err = Decode(qr.Matrix())
+	assert.Nil(t, err)
+	assert.Equal(t, data, res.Data)
+
+	// Literal % stay alphanumeric.
+	qr, err = NewQRGS1("(10)AB%%12%3(21)XY%Z", LevelM)
+	assert.Nil(t, err)
+	assert.Equal(t, []segment{{alpha, "10AB%%%%12%%3%21XY%%Z"}}, qr.segments)
+	res, err = Decode(qr.Matrix())
+	assert.Nil(t, err)
+	assert.Equal(t, "10AB%%12%3\x1d21XY%Z", res.Data)
+
+	_, err = NewQRGS1("(01)123", LevelM)
+	assert.NotNil(t, err)
+}
+
+func TestNewQRAIM(t *testing.T) {
+	qr, err := NewQRAIM("AA1234BBB112", LevelL, "37")
+	assert.Nil(t, err)
+	assert.Equal(t, FNC1Second, qr.FNC1)
+	assert.Equal(t, 37, qr.Application)
+
+	res, err := Decode(qr.Matrix())
+	assert.Nil(t, err)
+	assert.Equal(t, FNC1Second, res.FNC1)
+	assert.Equal(t, 37, res.Application)
+	assert.Equal(t, "AA1234BBB112", res.Data)
+
+	qr, _ = NewQRAIM("AB\x1d%CDEFGHIJ%%", LevelL, "37")
+	res, _ = Decode(qr.Matrix())
+	assert.Equal(t, "AB\x1d%CDEFGHIJ%%", res.Data)
+
+	qr, _ = NewQRAIM("DATA", LevelL, "a")
+	assert.Equal(t, 197, qr.Application)
+
+	for _, indicator := range []string{"", "1", "123", "-", "1a"} {
+		_, err := NewQRAIM("DATA", LevelL, indicator)
+		assert.NotNil(t, err, indicator)
+	}
+}
@@ -0,0 +1,92 @@
+package qrgo
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// Options of Image and WritePNG. The zero value draws every module as
+// a single black pixel on white, surrounded by the default quiet zone.
+type ImageOptions struct {
+	// Pixels per module, 0 for 1.
+	Scale int
+
+	// Width of the quiet zone in modules. 0 picks the minimum of the
+	// symbol type, see QuietZone, and negative widths draw none.
+	QuietZone int
+
+	// Colours of the dark and light modules, nil for black and white.
+	Foreground color.Color
+	Background color.Color
+}
+
+// The minimum width of the quiet zone in modules: 4 for QR symbols
+// and 2 for Micro QR and rMQR symbols.
+func (qr *QR) QuietZone() int {
+	if qr.Type == TypeMicro || qr.Type == TypeRMQR {
+		return 2
+	}
+	return 4
+}
+
+// Resolves the defaults of the quiet zone width.
+func (qr *QR) quietZone(width int) int {
+	if width == 0 {
+		return qr.QuietZone()
+	}
+	if width < 0 {
+		return 0
+	}
+	return width
+}
+
+// The symbol as a paletted image of two colours, with the light
+// background at index 0 and the dark foreground at index 1. A symbol
+// without modules gives an empty image.
+func (qr *QR) Image(opts ImageOptions) *image.Paletted {
+	scale, quiet := opts.Scale, qr.quietZone(opts.QuietZone)
+	if scale < 1 {
+		scale = 1
+	}
+	fg, bg := opts.Foreground, opts.Background
+	if fg == nil {
+		fg = color.Black
+	}
+	if bg == nil {
+		bg = color.White
+	}
+
+	if len(qr.Canvas) == 0 {
+		return image.NewPaletted(image.Rectangle{}, color.Palette{bg, fg})
+	}
+	rows, cols := len(qr.Canvas), len(qr.Canvas[0])
+	rect := image.Rect(0, 0, (cols+2*quiet)*scale, (rows+2*quiet)*scale)
+	img := image.NewPaletted(rect, color.Palette{bg, fg})
+	for r, row := range qr.Canvas {
+		for c, cell := range row {
+			if cell.color == 0 {
+				continue
+			}
+			x, y := (c+quiet)*scale, (r+quiet)*scale
+			for i := y; i < y+scale; i++ {
+				for j := x; j < x+scale; j++ {
+					img.SetColorIndex(j, i, 1)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// Writes the symbol as a PNG of one bit per pixel at the best
+// compression.
+func (qr *QR) WritePNG(w io.Writer, opts ImageOptions) error {
+	if len(qr.Canvas) == 0 {
+		return errors.New("Symbol without modules.")
+	}
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	return encoder.Encode(w, qr.Image(opts))
+}
@@ -0,0 +1,51 @@
+package qrgo
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImage(t *testing.T) {
+	qr, _ := NewQR("IMAGE")
+	img := qr.Image(ImageOptions{})
+	assert.Equal(t, 29, img.Bounds().Dx())
+	assert.Equal(t, 29, img.Bounds().Dy())
+	assert.Equal(t, uint8(0), img.ColorIndexAt(3, 3))
+	assert.Equal(t, uint8(1), img.ColorIndexAt(4, 4))
+	assert.Equal(t, uint8(0), img.ColorIndexAt(5, 5))
+
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	img = qr.Image(ImageOptions{Scale: 3, QuietZone: -1, Foreground: red})
+	assert.Equal(t, 63, img.Bounds().Dx())
+	assert.Equal(t, red, img.At(2, 2))
+	assert.Equal(t, color.White, img.At(3, 3))
+
+	micro, _ := NewMicroQR("1", LevelL)
+	assert.Equal(t, 15, micro.Image(ImageOptions{}).Bounds().Dx())
+	rmqr, _ := NewRMQRHeight("1", LevelM, 7)
+	img = rmqr.Image(ImageOptions{Scale: 2, QuietZone: 1})
+	assert.Equal(t, 90, img.Bounds().Dx())
+	assert.Equal(t, 18, img.Bounds().Dy())
+
+	assert.True(t, (&QR{}).Image(ImageOptions{}).Bounds().Empty())
+}
+
+func TestWritePNG(t *testing.T) {
+	qr, _ := NewQRLevel("https://example.com/labels/4711", LevelM)
+	var buf bytes.Buffer
+	assert.Nil(t, qr.WritePNG(&buf, ImageOptions{Scale: 4}))
+
+	img, err := png.Decode(&buf)
+	if assert.Nil(t, err) {
+		res, err := ReadImage(img)
+		if assert.Nil(t, err) {
+			assert.Equal(t, qr.Data, res.Data)
+		}
+	}
+
+	assert.NotNil(t, (&QR{}).WritePNG(&buf, ImageOptions{}))
+}
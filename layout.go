@@ -0,0 +1,107 @@
+package qrgo
+
+import "strconv"
+
+const (
+	formatGenerator  = 0x537  // x^10 + x^8 + x^5 + x^4 + x^2 + x + 1
+	formatMask       = 0x5412 // 101010000010010
+	microFormatMask  = 0x4445 // 100010001000101
+	versionGenerator = 0x1f25 // x^12 + x^11 + x^10 + x^9 + x^8 + x^5 + x^2 + 1
+)
+
+// Masks of the rMQR format information next to the finder pattern
+// and next to the finder sub pattern.
+var rmqrFormatMasks = [2]int{
+	0x1fab2, // 011111101010110010
+	0x20a7b, // 100000101001111011
+}
+
+// The two bits identifying the error correction level in the format
+// information. They do not follow the order of the levels.
+var levelIndicators = []int{LevelL: 1, LevelM: 0, LevelQ: 3, LevelH: 2}
+
+// Alignment pattern centres share the same row and column coordinates.
+// The first one is always 6 and the last one lies 7 modules away from
+// the opposite edge. One coordinate is added every seven versions and
+// all but the first two are spaced by the same even step.
+//
+//		Version 2:  6, 18
+//		Version 7:  6, 22, 38
+//		Version 32: 6, 34, 60, 86, 112, 138
+//
+func alignmentPositions(version int) []int {
+	if version < 2 {
+		return nil
+	}
+	num := version/7 + 2
+	modules := (version-1)*4 + 21
+	step := (version*8 + num*3 + 5) / (num*4 - 4) * 2
+
+	positions := make([]int, num)
+	positions[0] = 6
+	for i, pos := num-1, modules-7; i > 0; i, pos = i-1, pos-step {
+		positions[i] = pos
+	}
+	return positions
+}
+
+// Appends the remainder of dividing data by the generator polynomial
+// over GF(2), which yields a systematic BCH code word.
+func bch(data, generator int) int {
+	shift := nbit(generator) - 1
+	return data<<shift | polyDiv(data<<shift, generator)
+}
+
+// The format information holds two level bits followed by three mask
+// bits. They are extended to a BCH(15,5) code word and XORed with the
+// format mask, so that the result is never all zero.
+//
+//		Level M, mask 5:
+//			00101 -> 001010011011100 -> 100000011001110
+//
+func formatInformation(level, mask int) string {
+	bits := bch(levelIndicators[level]<<3|mask, formatGenerator) ^ formatMask
+	return padLeft(strconv.FormatInt(int64(bits), 2), 15)
+}
+
+// The version information of version 7 and up holds the six version
+// bits extended to a BCH(18,6) code word.
+//
+//		Version 7:
+//			000111 -> 000111110010010100
+//
+func versionInformation(version int) string {
+	bits := bch(version, versionGenerator)
+	return padLeft(strconv.FormatInt(int64(bits), 2), 18)
+}
+
+// The format information of Micro QR symbols holds the three bits of
+// the symbol number, which stands for version and level, followed by
+// two mask bits. It is extended like the one of QR symbols but masked
+// differently.
+//
+//		M2-L, mask 2:
+//			00110 -> 001101110000101 -> 101111111000000
+//
+func microFormatInformation(symbol, mask int) string {
+	bits := bch(symbol<<2|mask, formatGenerator) ^ microFormatMask
+	return padLeft(strconv.FormatInt(int64(bits), 2), 15)
+}
+
+// The format information of rMQR symbols holds the level bit, 0 for
+// M and 1 for H, followed by the five bits of the size index. It is
+// extended like the version information of QR symbols and masked
+// differently for each of its two copies.
+//
+//		R13x27-H:
+//			110000 -> 110000001010101101 -> 101111100000011111
+//										 -> 010000100011010110
+//
+func rmqrFormatInformation(level, version int) [2]string {
+	bits := bch(map[int]int{LevelM: 0, LevelH: 1}[level]<<5|version-1, versionGenerator)
+	var fis [2]string
+	for i, m := range rmqrFormatMasks {
+		fis[i] = padLeft(strconv.FormatInt(int64(bits^m), 2), 18)
+	}
+	return fis
+}
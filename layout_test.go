@@ -0,0 +1,39 @@
+package qrgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlignmentPositions(t *testing.T) {
+	assert.Equal(t, []int(nil), alignmentPositions(1))
+	assert.Equal(t, []int{6, 18}, alignmentPositions(2))
+	assert.Equal(t, []int{6, 34}, alignmentPositions(6))
+	assert.Equal(t, []int{6, 22, 38}, alignmentPositions(7))
+	assert.Equal(t, []int{6, 26, 46, 66}, alignmentPositions(14))
+	assert.Equal(t, []int{6, 28, 50, 72, 94}, alignmentPositions(21))
+	assert.Equal(t, []int{6, 34, 60, 86, 112, 138}, alignmentPositions(32))
+	assert.Equal(t, []int{6, 24, 50, 76, 102, 128, 154}, alignmentPositions(36))
+	assert.Equal(t, []int{6, 30, 58, 86, 114, 142, 170}, alignmentPositions(40))
+}
+
+func TestFormatInformation(t *testing.T) {
+	assert.Equal(t, "111011111000100", formatInformation(LevelL, 0))
+	assert.Equal(t, "110100101110110", formatInformation(LevelL, 7))
+	assert.Equal(t, "101010000010010", formatInformation(LevelM, 0))
+	// Doc example
+	assert.Equal(t, "100000011001110", formatInformation(LevelM, 5))
+	assert.Equal(t, "011010101011111", formatInformation(LevelQ, 0))
+	assert.Equal(t, "011101000000110", formatInformation(LevelQ, 3))
+	assert.Equal(t, "001011010001001", formatInformation(LevelH, 0))
+	assert.Equal(t, "000100000111011", formatInformation(LevelH, 7))
+}
+
+func TestVersionInformation(t *testing.T) {
+	// Doc example
+	assert.Equal(t, "000111110010010100", versionInformation(7))
+	assert.Equal(t, "001000010110111100", versionInformation(8))
+	assert.Equal(t, "010101011010000011", versionInformation(21))
+	assert.Equal(t, "101000110001101001", versionInformation(40))
+}
@@ -0,0 +1,266 @@
+package qrgo
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	// 1: # Data bits
+	// 2: # Error Correction Words
+	//
+	// M1 only detects errors and is listed at level L.
+	microBlockInfo = []map[int][2]int{
+		LevelL: {1: {20, 2}, 2: {40, 5}, 3: {84, 6}, 4: {128, 8}},
+		LevelM: {2: {32, 6}, 3: {68, 8}, 4: {112, 10}},
+		LevelQ: {4: {80, 14}},
+		LevelH: {},
+	}
+
+	// Symbol numbers of the format information.
+	microSymbols = []map[int]int{
+		LevelL: {1: 0, 2: 1, 3: 3, 4: 5},
+		LevelM: {2: 2, 3: 4, 4: 6},
+		LevelQ: {4: 7},
+	}
+
+	// Modes available in each version.
+	microModes = map[int][]int{
+		1: {numeric},
+		2: {numeric, alpha},
+		3: {numeric, alpha, byteMode, kanji},
+		4: {numeric, alpha, byteMode, kanji},
+	}
+
+	// Micro QR symbols use four of the QR masks.
+	microMasks = []int{1, 4, 6, 7}
+)
+
+// The mode indicator of Micro QR symbols is V - 1 bits long and
+// holds the index of the mode, M1 has none.
+//
+//		M4:
+//			Numeric:	000
+//			Alpha:		001
+//			Bytes:		010
+//			Kanji:		011
+//
+func microIndicator(mode, version int) string {
+	if version == 1 {
+		return ""
+	}
+	index := map[int]int64{numeric: 0, alpha: 1, byteMode: 2, kanji: 3}[mode]
+	return padLeft(strconv.FormatInt(index, 2), version-1)
+}
+
+// Number of bits of the count indicator.
+//
+//		M1 to M4:
+//			Numeric:	3, 4, 5, 6 bits
+//			Alpha:		3, 4, 5 bits from M2
+//			Bytes:		4, 5 bits from M3
+//			Kanji:		3, 4 bits from M3
+//
+func microCountBits(mode, version int) int {
+	switch mode {
+	case numeric:
+		return version + 2
+	case alpha:
+		return version + 1
+	case byteMode:
+		return version + 1
+	default:
+		return version
+	}
+}
+
+// Splits the data into the segments with the least number of bits in
+// the given version. Returns nil if the version can't encode the data.
+func microSegments(data string, version int) []segment {
+	return splitSegments(data, microModes[version], func(mode int) int {
+		return version - 1 + microCountBits(mode, version)
+	}, false)
+}
+
+// Total number of bits of the segments in the given version.
+func microBits(segments []segment, version int) int {
+	bits := 0
+	for _, s := range segments {
+		bits += version - 1 + microCountBits(s.mode, version) + s.dataBits()
+	}
+	return bits
+}
+
+// Appends the terminator of 2 * V + 1 zero bits as far as there is
+// room, then fills up the codeword with zeros and the remaining data
+// bits with the pad codewords. The data of M1 and M3 ends in a
+// codeword of only four bits, held in the upper half of the last byte.
+func microTerminator(encoding string, version, capacity int) []byte {
+	terminator := 2*version + 1
+	if terminator > capacity-len(encoding) {
+		terminator = capacity - len(encoding)
+	}
+	encoding += strings.Repeat("0", terminator)
+
+	if len(encoding)%8 != 0 {
+		end := (len(encoding)/8 + 1) * 8
+		if end > capacity {
+			end = capacity
+		}
+		encoding = padRight(encoding, end)
+	}
+	for i := 0; len(encoding)+8 <= capacity; i++ {
+		encoding += terminatorPads[i%2]
+	}
+	return encodingToByteArray(padRight(encoding, (capacity+7)/8*8))
+}
+
+// Chooses the smallest version holding the data at the level.
+func (qr *QR) microVersion() {
+	for v := 1; v <= 4; v++ {
+		info, ok := microBlockInfo[qr.Level][v]
+		if !ok {
+			continue
+		}
+		segments := microSegments(qr.Data, v)
+		// Micro QR has no ECI, so byte mode text is ISO-8859-1.
+		if segments == nil || beyondLatin1(segments) {
+			continue
+		}
+		latin1Segments(segments)
+		if microBits(segments, v) <= info[0] {
+			qr.Version, qr.segments = v, segments
+			qr.Mode, qr.Length = segments[0].mode, 0
+			for _, s := range segments {
+				qr.Length += s.length()
+			}
+			return
+		}
+	}
+}
+
+// Encodes the segments into a single block of data codewords
+// followed by its error correction codewords.
+func (qr *QR) microEncoding() {
+	capacity := microBlockInfo[qr.Level][qr.Version][0]
+	encoding := ""
+	for _, s := range qr.segments {
+		encoding += microIndicator(s.mode, qr.Version) +
+			padLeft(strconv.FormatInt(int64(s.length()), 2), microCountBits(s.mode, qr.Version)) +
+			s.encodeData()
+	}
+	qr.Encoding = microTerminator(encoding, qr.Version, capacity)
+
+	qr.Correction = make([]byte, qr.Errors)
+	NewRSEncoder(NewField(0x11d, 2), qr.Errors).ECC(qr.Encoding, qr.Correction)
+	qr.Interleaved = byteArrayToEncoding(qr.Encoding)[:capacity] +
+		byteArrayToEncoding(qr.Correction)
+}
+
+// Micro QR symbols have a single finder pattern in the top-left
+// corner. The timing patterns run along the top and left edges.
+//
+//		M1:
+//		11111110101
+//		10000010000
+//		10111010000
+//		10111010000
+//		10111010000
+//		10000010000
+//		11111110000
+//		00000000000
+//		10000000000
+//		00000000000
+//		10000000000
+//
+func (qr *QR) drawMicroFunctionPatterns() {
+	qr.Canvas = newCanvas(qr.Modules, qr.Modules)
+	drawPattern(qr.Canvas, 0, 0, 7)
+	drawSeperator(qr.Canvas, 7, 7, -1, -1)
+	drawTiming(qr.Canvas, 0, 8, qr.Modules)
+
+	for i := 1; i <= 8; i++ {
+		qr.Canvas[8][i].data = false
+		qr.Canvas[i][8].data = false
+	}
+}
+
+// Without timing patterns along the right and bottom edges, the
+// mask should leave many dark modules there. The sums of the dark
+// modules along both edges are scored as 16 * smaller + larger
+// and the mask with the highest score wins.
+func (qr *QR) microMasking() {
+	last := qr.Modules - 1
+	winner, best := [][]*Cell{}, -1
+	for i, m := range microMasks {
+		masked := maskCanvas(qr.Canvas, masks[m])
+		right, bottom := 0, 0
+		for k := 1; k <= last; k++ {
+			right += masked[k][last].color
+			bottom += masked[last][k].color
+		}
+		score := 16*right + bottom
+		if bottom < right {
+			score = 16*bottom + right
+		}
+		if score > best {
+			winner, qr.Mask, best = masked, i, score
+		}
+	}
+	qr.Canvas = winner
+}
+
+// The format information runs from the left edge along the row
+// below the finder pattern and then up the column next to it, most
+// significant bit first, just like the first copy in QR symbols.
+//
+//		Bit 14 to 8: row 8, columns 1 to 7
+//		Bit 7 to 0:  column 8, rows 8 to 1
+//
+func (qr *QR) drawMicroFormatInformation() {
+	fis := microFormatInformation(microSymbols[qr.Level][qr.Version], qr.Mask)
+	for i := 0; i < 15; i++ {
+		num, _ := strconv.Atoi(string(fis[i]))
+		if i < 7 {
+			qr.Canvas[8][i+1].color = num
+		} else {
+			qr.Canvas[15-i][8].color = num
+		}
+	}
+}
+
+// Encode data as a Micro QR symbol at the given error correction
+// level. The smallest of the versions M1 to M4 that holds the data
+// at that level is chosen and stored as version 1 to 4. M1 only
+// detects errors and is used at level L, level Q is only available
+// in M4 and level H not at all. The mask is one of four and stored
+// as such. Without ECI, text in byte mode must lie within ISO-8859-1.
+func NewMicroQR(data string, level int) (*QR, error) {
+	if len(data) == 0 {
+		return nil, errors.New("Empty data input.")
+	}
+	if level < LevelL || level > LevelQ {
+		return nil, errors.New("Unknown error correction level for Micro QR.")
+	}
+
+	qr := QR{Type: TypeMicro, Data: data, Level: level}
+	qr.microVersion()
+	if qr.Version == 0 {
+		if segments := microSegments(data, 4); segments != nil && beyondLatin1(segments) {
+			return nil, errors.New("Data input not representable in ISO-8859-1 for Micro QR.")
+		}
+		return nil, errors.New("Data input too long for Micro QR.")
+	}
+	qr.Modules = 2*qr.Version + 9
+	qr.Width = qr.Modules
+	qr.Errors = microBlockInfo[qr.Level][qr.Version][1]
+
+	qr.microEncoding()
+	qr.Block1, qr.Words1 = 1, len(qr.Encoding)
+	qr.drawMicroFunctionPatterns()
+	qr.drawDataBits()
+	qr.microMasking()
+	qr.drawMicroFormatInformation()
+	return &qr, nil
+}
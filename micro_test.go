@@ -0,0 +1,155 @@
+package qrgo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMicroFormatInformation(t *testing.T) {
+	// Doc example
+	assert.Equal(t, "101111111000000", microFormatInformation(1, 2))
+	assert.Equal(t, "100010001000101", microFormatInformation(0, 0))
+	assert.Equal(t, "001100001101100", microFormatInformation(5, 3))
+	assert.Equal(t, "011000111010100", microFormatInformation(7, 1))
+}
+
+func TestMicroIndicators(t *testing.T) {
+	assert.Equal(t, "", microIndicator(numeric, 1))
+	assert.Equal(t, "1", microIndicator(alpha, 2))
+	assert.Equal(t, "10", microIndicator(byteMode, 3))
+	assert.Equal(t, "011", microIndicator(kanji, 4))
+	assert.Equal(t, 3, microCountBits(numeric, 1))
+	assert.Equal(t, 6, microCountBits(numeric, 4))
+	assert.Equal(t, 3, microCountBits(alpha, 2))
+	assert.Equal(t, 5, microCountBits(byteMode, 4))
+	assert.Equal(t, 3, microCountBits(kanji, 3))
+}
+
+func TestMicroTerminator(t *testing.T) {
+	// M1 ends in a codeword of four zero bits.
+	assert.Equal(t, []byte{0x20, 0xec, 0}, microTerminator("001", 1, 20))
+	assert.Equal(t, []byte{0xff, 0, 0}, microTerminator("11111111000", 1, 20))
+	// The terminator is cut short at the capacity.
+	assert.Equal(t, []byte{0xff, 0xff, 0xf0}, microTerminator(strings.Repeat("1", 20), 1, 20))
+	assert.Equal(t, []byte{0x80, 0xec, 0x11, 0xec}, microTerminator("1", 2, 32))
+}
+
+func TestNewMicroQR(t *testing.T) {
+	// Example from the specification.
+	qr, err := NewMicroQR("01234567", LevelL)
+	assert.Nil(t, err)
+	assert.Equal(t, TypeMicro, qr.Type)
+	assert.Equal(t, 2, qr.Version)
+	assert.Equal(t, 13, qr.Modules)
+	assert.Equal(t, []byte{0x40, 0x18, 0xac, 0xc3, 0x00}, qr.Encoding)
+	assert.Equal(t, []byte{0x86, 0x0d, 0x22, 0xae, 0x30}, qr.Correction)
+
+	// The codewords of the example placed, masked and evaluated by
+	// hand: mask 01 and format information 101000010011001, with bit 0
+	// next to the top edge.
+	assert.Equal(t, 1, qr.Mask)
+	assert.Equal(t, parseMatrix(
+		"#######.#.#.#",
+		"#.....#.###.#",
+		"#.###.#..##.#",
+		"#.###.#..####",
+		"#.###.#.###..",
+		"#.....#.#...#",
+		"#######..####",
+		".........##..",
+		"##.#....#...#",
+		".##.#.#.#.#.#",
+		"###..#######.",
+		"...#.#....##.",
+		"###.#..##.###",
+	), qr.Matrix())
+}
+
+// The module matrix drawn by rows of '#' for dark and '.' for light
+// modules.
+func parseMatrix(rows ...string) [][]bool {
+	matrix := make([][]bool, len(rows))
+	for r, row := range rows {
+		matrix[r] = make([]bool, len(row))
+		for c := range row {
+			matrix[r][c] = row[c] == '#'
+		}
+	}
+	return matrix
+}
+
+func TestMicroCapacity(t *testing.T) {
+	tests := []struct {
+		data    string
+		level   int
+		version int
+	}{
+		{"12345", LevelL, 1},
+		{"123456", LevelL, 2},
+		{"ABCDEF", LevelL, 2},
+		{"ABCDEF", LevelM, 3},
+		{"abc", LevelL, 3},
+		{strings.Repeat("1", 23), LevelL, 3},
+		{strings.Repeat("1", 35), LevelL, 4},
+		{strings.Repeat("A", 21), LevelL, 4},
+		{strings.Repeat("a", 15), LevelL, 4},
+		{strings.Repeat("漢", 9), LevelL, 4},
+		{strings.Repeat("1", 21), LevelQ, 4},
+		{strings.Repeat("a", 9), LevelQ, 4},
+	}
+	for _, test := range tests {
+		qr, err := NewMicroQR(test.data, test.level)
+		if assert.Nil(t, err, test.data) {
+			assert.Equal(t, test.version, qr.Version, test.data)
+			assert.Equal(t, 2*test.version+9, len(qr.Canvas))
+		}
+	}
+
+	for _, data := range []string{strings.Repeat("1", 36), strings.Repeat("a", 16), ""} {
+		_, err := NewMicroQR(data, LevelL)
+		assert.NotNil(t, err, data)
+	}
+	_, err := NewMicroQR(strings.Repeat("1", 22), LevelQ)
+	assert.NotNil(t, err)
+	_, err = NewMicroQR("1", LevelH)
+	assert.NotNil(t, err)
+}
+
+func TestMicroCharset(t *testing.T) {
+	qr, err := NewMicroQR("Zürich", LevelL)
+	assert.Nil(t, err)
+	assert.Equal(t, []segment{{byteMode, "Z\xfcrich"}}, qr.segments)
+	assert.Equal(t, 6, qr.Length)
+
+	// Kanji mode needs no ECI, byte mode would.
+	_, err = NewMicroQR("漢字", LevelL)
+	assert.Nil(t, err)
+	_, err = NewMicroQR("Ελλάδα", LevelL)
+	assert.EqualError(t, err, "Data input not representable in ISO-8859-1 for Micro QR.")
+	_, err = NewMicroQR(strings.Repeat("a", 16), LevelL)
+	assert.EqualError(t, err, "Data input too long for Micro QR.")
+}
+
+func TestMicroMasking(t *testing.T) {
+	qr, _ := NewMicroQR("MICRO", LevelM)
+	assert.True(t, qr.Mask >= 0 && qr.Mask < 4)
+
+	// No other mask leaves more dark modules along the edges.
+	last := qr.Modules - 1
+	score := func(canvas [][]*Cell) int {
+		right, bottom := 0, 0
+		for k := 1; k <= last; k++ {
+			right += canvas[k][last].color
+			bottom += canvas[last][k].color
+		}
+		return 16*min(right, bottom) + max(right, bottom)
+	}
+	best := score(qr.Canvas)
+	for _, m := range microMasks {
+		// Masking twice with the same mask removes it again.
+		unmasked := maskCanvas(qr.Canvas, masks[microMasks[qr.Mask]])
+		assert.True(t, score(maskCanvas(unmasked, masks[m])) <= best)
+	}
+}
@@ -0,0 +1,108 @@
+package qrgo
+
+import (
+	"errors"
+	"image"
+)
+
+// Number of symbols ReadImageAll looks for at most.
+const maxSymbols = 16
+
+// A symbol found in an image together with its position.
+type Symbol struct {
+	Result
+
+	// Outer corners of the symbol in pixels, without the quiet zone.
+	// They are ordered top-left, top-right, bottom-right and
+	// bottom-left as seen on the symbol, whichever way it is rotated.
+	Corners [4][2]float64
+}
+
+// Reports whether the point lies inside the convex quadrilateral.
+func inside(quad [4][2]float64, x, y float64) bool {
+	sign := 0.0
+	for i := range quad {
+		a, b := quad[i], quad[(i+1)%4]
+		cross := (b[0]-a[0])*(y-a[1]) - (b[1]-a[1])*(x-a[0])
+		if cross*sign < 0 {
+			return false
+		}
+		if cross != 0 {
+			sign = cross
+		}
+	}
+	return true
+}
+
+// The centre of the symbol in pixels.
+func (s *Symbol) centre() (float64, float64) {
+	x, y := 0.0, 0.0
+	for _, c := range s.Corners {
+		x += c[0] / 4
+		y += c[1] / 4
+	}
+	return x, y
+}
+
+// Decodes every symbol in a binarized image. Triples are tried from
+// the best fit to the worst, skipping those sharing a pattern with a
+// symbol already decoded. The number of attempts grows with the
+// number of patterns, as each symbol may need a few.
+func readSymbols(bits [][]bool) []*Symbol {
+	patterns := findFinderPatterns(bits)
+	attempts := maxAttempts * (len(patterns)/3 + 1)
+	used := map[*finderPattern]bool{}
+
+	symbols := []*Symbol{}
+	for _, t := range finderTriples(patterns, 3*maxSymbols) {
+		if attempts == 0 || len(symbols) == maxSymbols {
+			break
+		}
+		if used[t[0]] || used[t[1]] || used[t[2]] {
+			continue
+		}
+		attempts--
+		if sym, err := decodeAt(bits, t[0], t[1], t[2]); err == nil {
+			used[t[0]], used[t[1]], used[t[2]] = true, true, true
+			symbols = append(symbols, sym)
+		}
+	}
+	return symbols
+}
+
+// ReadImageAll locates and decodes every symbol in an image, such as
+// several labels photographed together, in the way of ReadImage.
+//
+// Finder patterns are grouped into triples by how well they fit the
+// corners of a symbol and every triple is decoded on its own. Each
+// pattern belongs to one symbol only. Symbols found by more than one
+// of the binarizations are returned once.
+func ReadImageAll(img image.Image) ([]*Symbol, error) {
+	if img.Bounds().Empty() {
+		return nil, errors.New("Empty image.")
+	}
+
+	// The binarized images start at 0, 0 whatever the bounds.
+	min := img.Bounds().Min
+	symbols := []*Symbol{}
+	for _, bits := range binarizations(img) {
+		for _, sym := range readSymbols(bits) {
+			for i := range sym.Corners {
+				sym.Corners[i][0] += float64(min.X)
+				sym.Corners[i][1] += float64(min.Y)
+			}
+			x, y := sym.centre()
+			known := false
+			for _, s := range symbols {
+				known = known || inside(s.Corners, x, y)
+			}
+			if !known && len(symbols) < maxSymbols {
+				symbols = append(symbols, sym)
+			}
+		}
+	}
+	if len(symbols) == 0 {
+		return nil, errors.New("No symbols found.")
+	}
+	return symbols, nil
+}
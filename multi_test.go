@@ -0,0 +1,91 @@
+package qrgo
+
+import (
+	"image"
+	"image/draw"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInside(t *testing.T) {
+	quad := [4][2]float64{{10, 10}, {50, 20}, {40, 60}, {0, 40}}
+	assert.True(t, inside(quad, 25, 30))
+	assert.True(t, inside(quad, 10, 10))
+	assert.False(t, inside(quad, 5, 5))
+	assert.False(t, inside(quad, 60, 40))
+
+	// The order of the corners does not matter.
+	quad[1], quad[3] = quad[3], quad[1]
+	assert.True(t, inside(quad, 25, 30))
+	assert.False(t, inside(quad, 60, 40))
+}
+
+func TestReadImageAll(t *testing.T) {
+	tests := []struct {
+		data  string
+		level int
+		angle float64
+		x, y  int
+	}{
+		{"PALLET 1 OF 3", LevelM, 0, 0, 0},
+		{"https://example.com/pallet/2", LevelQ, 0.5, 240, 20},
+		{"PALLET 3 OF 3 STACKED ON EURO PALLET", LevelL, -2, 60, 260},
+	}
+	img := image.NewGray(image.Rect(0, 0, 500, 500))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	want := []string{}
+	for _, test := range tests {
+		qr, _ := NewQRLevel(test.data, test.level)
+		symbol := renderSymbol(qr.Matrix(), 4, test.angle, 220)
+		r := image.Rect(test.x, test.y, test.x+220, test.y+220)
+		draw.Draw(img, r, symbol, image.Point{}, draw.Src)
+		want = append(want, test.data)
+	}
+
+	symbols, err := ReadImageAll(img)
+	assert.Nil(t, err)
+	got := []string{}
+	for _, sym := range symbols {
+		got = append(got, sym.Data)
+		if sym.Data == tests[0].data {
+			// 21 modules of 4 pixels around the centre at 110.
+			assert.InDelta(t, 68, sym.Corners[0][0], 1.5)
+			assert.InDelta(t, 68, sym.Corners[0][1], 1.5)
+			assert.InDelta(t, 152, sym.Corners[2][0], 1.5)
+			assert.InDelta(t, 152, sym.Corners[2][1], 1.5)
+		}
+	}
+	sort.Strings(want)
+	sort.Strings(got)
+	assert.Equal(t, want, got)
+}
+
+func TestReadImageAllSubImage(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 400, 400))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	for _, pos := range []image.Point{{180, 20}, {180, 180}} {
+		qr, _ := NewQR("PALLET AT " + pos.String())
+		r := image.Rectangle{pos, pos.Add(image.Pt(220, 220))}
+		draw.Draw(img, r, renderSymbol(qr.Matrix(), 4, 0, 220), image.Point{}, draw.Src)
+	}
+
+	// Corners are given in the coordinates of the image.
+	sub := img.SubImage(image.Rect(150, 150, 400, 400))
+	symbols, err := ReadImageAll(sub)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(symbols))
+	assert.Equal(t, "PALLET AT (180,180)", symbols[0].Data)
+	assert.InDelta(t, 180+68, symbols[0].Corners[0][0], 1.5)
+	assert.InDelta(t, 180+68, symbols[0].Corners[0][1], 1.5)
+	assert.InDelta(t, 180+152, symbols[0].Corners[2][0], 1.5)
+	assert.InDelta(t, 180+152, symbols[0].Corners[2][1], 1.5)
+}
+
+func TestReadImageAllBlank(t *testing.T) {
+	_, err := ReadImageAll(image.NewGray(image.Rect(0, 0, 100, 100)))
+	assert.NotNil(t, err)
+	_, err = ReadImageAll(image.NewGray(image.Rect(0, 0, 0, 0)))
+	assert.NotNil(t, err)
+}
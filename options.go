@@ -0,0 +1,109 @@
+package qrgo
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Options of NewQRWithOptions. The zero value encodes at level L in
+// the smallest version holding the data, with the mask of the least
+// penalty, just like NewQR.
+type Options struct {
+	Level int
+
+	// Version fixes the version. Otherwise the smallest version from
+	// MinVersion to MaxVersion holding the data is chosen, where 0
+	// leaves the bound open.
+	Version    int
+	MinVersion int
+	MaxVersion int
+
+	// With ForceMask, Mask is applied instead of the mask of the
+	// least penalty.
+	Mask      int
+	ForceMask bool
+
+	// BoostLevel raises the level as far as the data still fits into
+	// the chosen version.
+	BoostLevel bool
+
+	// ECI fixes the character set of byte mode text: ISO-8859-1
+	// behind the header of ECILatin1 or without one for NoECI, where
+	// text beyond it is an error, or UTF-8 behind the header of
+	// ECIUTF8. Data in other character sets is written by NewQRECI.
+	// 0 chooses the character set as in NewQRLevel.
+	ECI int
+}
+
+var levelNames = []string{LevelL: "L", LevelM: "M", LevelQ: "Q", LevelH: "H"}
+
+// Encode data according to the options. Byte mode text is written in
+// ISO-8859-1 or UTF-8 as in NewQRLevel, unless the ECI is given.
+func NewQRWithOptions(data string, opts Options) (*QR, error) {
+	if len(data) == 0 {
+		return nil, errors.New("Empty data input.")
+	}
+	if opts.Level < LevelL || opts.Level > LevelH {
+		return nil, errors.New("Unknown error correction level.")
+	}
+	if opts.ForceMask && (opts.Mask < 0 || opts.Mask >= len(masks)) {
+		return nil, errors.New("Unknown mask " + strconv.Itoa(opts.Mask) + ".")
+	}
+
+	switch opts.ECI {
+	case 0, NoECI, ECILatin1, ECIUTF8:
+	default:
+		return nil, errors.New("Unsupported ECI designator " + strconv.Itoa(opts.ECI) + ".")
+	}
+
+	min, max := opts.MinVersion, opts.MaxVersion
+	if min == 0 {
+		min = 1
+	}
+	if max == 0 {
+		max = versions
+	}
+	if opts.Version != 0 {
+		if opts.Version < min || opts.Version > max {
+			return nil, errors.New("Version " + strconv.Itoa(opts.Version) +
+				" lies outside of the version bounds.")
+		}
+		min, max = opts.Version, opts.Version
+	}
+	if min < 1 || max > versions || min > max {
+		return nil, errors.New("Invalid versions " + strconv.Itoa(min) +
+			" to " + strconv.Itoa(max) + ".")
+	}
+
+	qr := QR{Data: data, Level: opts.Level, Mask: opts.Mask, fixedMask: opts.ForceMask}
+	if opts.ECI != 0 {
+		qr.fixedECI = true
+		if opts.ECI != NoECI {
+			qr.ECI = opts.ECI
+		}
+	}
+	qr.versionBetween(qr.segmentText, min, max)
+	if qr.Version > max {
+		bound := "version " + strconv.Itoa(max)
+		if min != max {
+			bound = "versions " + strconv.Itoa(min) + " to " + strconv.Itoa(max)
+		}
+		return nil, errors.New("Data input too long for " + bound +
+			" at level " + levelNames[qr.Level] + ".")
+	}
+	// The segments are checked as split, before the conversion.
+	if qr.fixedECI && qr.ECI != ECIUTF8 && beyondLatin1(segmentData(data, qr.Version, false)) {
+		return nil, errors.New("Data input not representable in ISO-8859-1.")
+	}
+
+	if opts.BoostLevel {
+		bits := qr.headerBits() + segmentBits(qr.segments, qr.Version)
+		for level := LevelH; level > qr.Level; level-- {
+			if bits <= blockInfo[level][qr.Version][0]*8 {
+				qr.Level = level
+				break
+			}
+		}
+	}
+	return qr.build()
+}
@@ -0,0 +1,113 @@
+package qrgo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewQRWithOptions(t *testing.T) {
+	qr, err := NewQRWithOptions("HELLO WORLD", Options{})
+	assert.Nil(t, err)
+	expected, _ := NewQR("HELLO WORLD")
+	assert.Equal(t, expected.Matrix(), qr.Matrix())
+
+	qr, err = NewQRWithOptions("HELLO WORLD", Options{Version: 5, Level: LevelM})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, qr.Version)
+	assert.Equal(t, 37, len(qr.Canvas))
+
+	qr, err = NewQRWithOptions("HELLO WORLD", Options{MinVersion: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, qr.Version)
+
+	// The count indicators widen from version 10 on.
+	qr, err = NewQRWithOptions(strings.Repeat("1", 100), Options{MinVersion: 12})
+	assert.Nil(t, err)
+	assert.Equal(t, 12, qr.Version)
+
+	_, err = NewQRWithOptions(strings.Repeat("a", 100), Options{MaxVersion: 4})
+	assert.EqualError(t, err, "Data input too long for versions 1 to 4 at level L.")
+	_, err = NewQRWithOptions(strings.Repeat("a", 100), Options{Version: 3, Level: LevelH})
+	assert.EqualError(t, err, "Data input too long for version 3 at level H.")
+	_, err = NewQRWithOptions("a", Options{Version: 3, MinVersion: 4})
+	assert.NotNil(t, err)
+	_, err = NewQRWithOptions("a", Options{MinVersion: 5, MaxVersion: 4})
+	assert.NotNil(t, err)
+	_, err = NewQRWithOptions("a", Options{MaxVersion: 41})
+	assert.NotNil(t, err)
+	_, err = NewQRWithOptions("a", Options{Level: 4})
+	assert.NotNil(t, err)
+}
+
+func TestOptionsMask(t *testing.T) {
+	for mask := 0; mask < 8; mask++ {
+		qr, err := NewQRWithOptions("MASK", Options{Mask: mask, ForceMask: true})
+		assert.Nil(t, err)
+		assert.Equal(t, mask, qr.Mask)
+
+		res, err := Decode(qr.Matrix())
+		if assert.Nil(t, err) {
+			assert.Equal(t, mask, res.Mask)
+			assert.Equal(t, "MASK", res.Data)
+		}
+	}
+
+	_, err := NewQRWithOptions("MASK", Options{Mask: 8, ForceMask: true})
+	assert.EqualError(t, err, "Unknown mask 8.")
+}
+
+func TestOptionsBoostLevel(t *testing.T) {
+	// 11 alphanumeric chars fit version 1 up to level Q.
+	qr, err := NewQRWithOptions("HELLO WORLD", Options{BoostLevel: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, qr.Version)
+	assert.Equal(t, LevelQ, qr.Level)
+
+	qr, err = NewQRWithOptions("HELLO WORLD", Options{Version: 2, BoostLevel: true})
+	assert.Nil(t, err)
+	assert.Equal(t, LevelH, qr.Level)
+
+	qr, err = NewQRWithOptions(strings.Repeat("a", 17), Options{BoostLevel: true})
+	assert.Nil(t, err)
+	assert.Equal(t, LevelL, qr.Level)
+}
+
+func TestOptionsECI(t *testing.T) {
+	// ISO-8859-1 without header, even where NewQR would pick UTF-8.
+	qr, err := NewQRWithOptions("Zürich", Options{ECI: NoECI})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, qr.ECI)
+	res, _ := Decode(qr.Matrix())
+	assert.Equal(t, 0, res.ECI)
+	assert.Equal(t, "Zürich", res.Data)
+
+	_, err = NewQRWithOptions("Ελλάδα", Options{ECI: NoECI})
+	assert.EqualError(t, err, "Data input not representable in ISO-8859-1.")
+	_, err = NewQRWithOptions("Zürich Ελλάδα", Options{ECI: ECILatin1})
+	assert.NotNil(t, err)
+
+	// The header of the designator, at level M and segmented.
+	qr, err = NewQRWithOptions("Zürich 8001", Options{ECI: ECILatin1, Level: LevelM})
+	assert.Nil(t, err)
+	assert.Equal(t, ECILatin1, qr.ECI)
+	assert.True(t, len(qr.segments) > 1)
+	res, _ = Decode(qr.Matrix())
+	assert.Equal(t, ECILatin1, res.ECI)
+	assert.Equal(t, LevelM, res.Level)
+	assert.Equal(t, "Zürich 8001", res.Data)
+	assert.Equal(t, []byte("Z\xfcrich "), res.Bytes)
+
+	// UTF-8 even where ISO-8859-1 would do.
+	qr, _ = NewQRWithOptions("Zürich", Options{ECI: ECIUTF8})
+	assert.Equal(t, ECIUTF8, qr.ECI)
+	res, _ = Decode(qr.Matrix())
+	assert.Equal(t, "Zürich", res.Data)
+	assert.Equal(t, []byte("Zürich"), res.Bytes)
+
+	_, err = NewQRWithOptions("a", Options{ECI: 9})
+	assert.EqualError(t, err, "Unsupported ECI designator 9.")
+	_, err = NewQRWithOptions("a", Options{ECI: -2})
+	assert.NotNil(t, err)
+}
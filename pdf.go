@@ -0,0 +1,301 @@
+package qrgo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Physical lengths in PDF points.
+const (
+	Inch       = 72.0
+	Millimetre = Inch / 25.4
+)
+
+// Options of WritePDF.
+type PDFOptions struct {
+	// Width of the symbol including its quiet zone in points, e.g.
+	// 20 * Millimetre. 0 draws every module 1 mm wide.
+	Width float64
+
+	// Width of the quiet zone in modules, as in ImageOptions.
+	QuietZone int
+}
+
+// A sheet of equally sized labels in a grid. All lengths are in points.
+type LabelSheet struct {
+	PageWidth  float64
+	PageHeight float64
+	Columns    int
+	Rows       int
+
+	LabelWidth  float64
+	LabelHeight float64
+
+	// Offset of the top-left label from the top-left page corner and
+	// distance between the top-left corners of neighbouring labels.
+	Left   float64
+	Top    float64
+	PitchX float64
+	PitchY float64
+
+	// Margin kept free on every label and size of the caption font.
+	Padding  float64
+	FontSize float64
+}
+
+// Common label sheets.
+var (
+	// Avery 5160 and compatibles: Letter, 3x10 labels of 2.625 x 1 in.
+	Avery5160 = LabelSheet{
+		PageWidth: 8.5 * Inch, PageHeight: 11 * Inch, Columns: 3, Rows: 10,
+		LabelWidth: 2.625 * Inch, LabelHeight: 1 * Inch,
+		Left: 0.1875 * Inch, Top: 0.5 * Inch, PitchX: 2.75 * Inch, PitchY: 1 * Inch,
+		Padding: 0.0625 * Inch, FontSize: 7,
+	}
+
+	// Avery L7160 and compatibles: A4, 3x7 labels of 63.5 x 38.1 mm.
+	AveryL7160 = LabelSheet{
+		PageWidth: 210 * Millimetre, PageHeight: 297 * Millimetre, Columns: 3, Rows: 7,
+		LabelWidth: 63.5 * Millimetre, LabelHeight: 38.1 * Millimetre,
+		Left: 7.2 * Millimetre, Top: 15.15 * Millimetre,
+		PitchX: 66.04 * Millimetre, PitchY: 38.1 * Millimetre,
+		Padding: 2 * Millimetre, FontSize: 8,
+	}
+)
+
+// A symbol to print on a label, with an optional caption below it.
+type Label struct {
+	Symbol  *QR
+	Caption string
+}
+
+// Widths of the chars ' ' to '~' of Helvetica in thousandths of the
+// font size.
+var helveticaWidths = []int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333,
+	278, 278, 556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278,
+	584, 584, 584, 556, 1015, 667, 667, 722, 722, 667, 611, 778, 722, 278,
+	500, 667, 556, 833, 722, 778, 667, 778, 722, 667, 611, 722, 667, 944,
+	667, 667, 611, 278, 278, 278, 469, 556, 333, 556, 556, 500, 556, 556,
+	278, 556, 556, 222, 222, 500, 222, 833, 556, 556, 556, 556, 333, 500,
+	278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// A PDF document under construction. Objects are numbered from 1 in
+// the order they are added.
+type pdfDocument struct {
+	objects []string
+}
+
+func (d *pdfDocument) add(body string) int {
+	d.objects = append(d.objects, body)
+	return len(d.objects)
+}
+
+// Adds a stream compressed by the Flate filter.
+func (d *pdfDocument) stream(data string) int {
+	var buf bytes.Buffer
+	z := zlib.NewWriter(&buf)
+	z.Write([]byte(data))
+	z.Close()
+	return d.add("<< /Length " + strconv.Itoa(buf.Len()) + " /Filter /FlateDecode >>\nstream\n" +
+		buf.String() + "\nendstream")
+}
+
+// Adds one page of the given size per content stream, followed by the
+// page tree and the catalog, and writes the document with its cross
+// reference table.
+func (d *pdfDocument) write(w io.Writer, width, height float64, contents []string, font bool) error {
+	resources := "<< >>"
+	if font {
+		f := d.add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+		resources = "<< /Font << /F1 " + strconv.Itoa(f) + " 0 R >> >>"
+	}
+	pages := d.add("")
+	kids := []string{}
+	for _, content := range contents {
+		c := d.stream(content)
+		page := d.add("<< /Type /Page /Parent " + strconv.Itoa(pages) + " 0 R /MediaBox [0 0 " +
+			pdfNumber(width) + " " + pdfNumber(height) + "] /Resources " + resources +
+			" /Contents " + strconv.Itoa(c) + " 0 R >>")
+		kids = append(kids, strconv.Itoa(page)+" 0 R")
+	}
+	d.objects[pages-1] = "<< /Type /Pages /Kids [" + strings.Join(kids, " ") +
+		"] /Count " + strconv.Itoa(len(kids)) + " >>"
+	catalog := d.add("<< /Type /Catalog /Pages " + strconv.Itoa(pages) + " 0 R >>")
+
+	out := bufio.NewWriter(w)
+	offset, offsets := 0, []int{}
+	write := func(s string) {
+		out.WriteString(s)
+		offset += len(s)
+	}
+	write("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	for i, body := range d.objects {
+		offsets = append(offsets, offset)
+		write(strconv.Itoa(i+1) + " 0 obj\n" + body + "\nendobj\n")
+	}
+	xref := offset
+	write("xref\n0 " + strconv.Itoa(len(d.objects)+1) + "\n0000000000 65535 f \n")
+	for _, o := range offsets {
+		write(padLeft(strconv.Itoa(o), 10) + " 00000 n \n")
+	}
+	write("trailer\n<< /Size " + strconv.Itoa(len(d.objects)+1) + " /Root " +
+		strconv.Itoa(catalog) + " 0 R >>\nstartxref\n" + strconv.Itoa(xref) + "\n%%EOF\n")
+	return out.Flush()
+}
+
+// Formats lengths to a hundredth of a thousandth of a point.
+func pdfNumber(f float64) string {
+	return strconv.FormatFloat(math.Round(f*1e5)/1e5, 'f', -1, 64)
+}
+
+// Content stream operators filling the dark modules of the symbol
+// with the top-left module at x, y from the top-left page corner.
+func (qr *QR) pdfContent(x, y, module, pageHeight float64) string {
+	var b strings.Builder
+	b.WriteString("q " + pdfNumber(module) + " 0 0 " + pdfNumber(-module) + " " +
+		pdfNumber(x) + " " + pdfNumber(pageHeight-y) + " cm\n")
+	for _, path := range outlines(qr.Canvas) {
+		for i, p := range path {
+			b.WriteString(strconv.Itoa(p.x) + " " + strconv.Itoa(p.y))
+			if i == 0 {
+				b.WriteString(" m\n")
+			} else {
+				b.WriteString(" l\n")
+			}
+		}
+		b.WriteString("h\n")
+	}
+	b.WriteString("f Q\n")
+	return b.String()
+}
+
+// Chars of the WinAnsiEncoding codes 0x80 to 0x9F, which hold control
+// chars in Latin-1. Unassigned codes hold a NUL character.
+var winAnsiChars = []rune("€\x00‚ƒ„…†‡ˆ‰Š‹Œ\x00Ž\x00\x00‘’“”•–—˜™š›œ\x00žŸ")
+
+// The WinAnsiEncoding code of a char, if it has one. Other codes are
+// those of Latin-1, without its control chars.
+func winAnsi(c rune) (byte, bool) {
+	if c >= ' ' && c <= '~' || c >= 0xa0 && c <= 0xff {
+		return byte(c), true
+	}
+	for i, w := range winAnsiChars {
+		if w == c && c != 0 {
+			return byte(0x80 + i), true
+		}
+	}
+	return 0, false
+}
+
+// Encodes the caption for a PDF string in WinAnsiEncoding, replacing
+// chars without a code by '?', and returns its width in thousandths of
+// the font size.
+//
+//		Größe “A” 漢 -> Gr\366\337e \223A\224 ?
+//
+func pdfText(caption string) (string, int) {
+	var b strings.Builder
+	width := 0
+	for _, c := range caption {
+		code, ok := winAnsi(c)
+		switch {
+		case !ok:
+			code = '?'
+			b.WriteByte(code)
+		case code == '(' || code == ')' || code == '\\':
+			b.WriteString("\\" + string(c))
+		case code > '~':
+			b.WriteString("\\" + strconv.FormatInt(int64(code), 8))
+		default:
+			b.WriteByte(code)
+		}
+		if code <= '~' {
+			width += helveticaWidths[code-' ']
+		} else {
+			width += 556
+		}
+	}
+	return b.String(), width
+}
+
+// Writes the symbol as a single page PDF of the symbol's size,
+// drawing the dark modules as vector fills.
+func (qr *QR) WritePDF(w io.Writer, opts PDFOptions) error {
+	if len(qr.Canvas) == 0 {
+		return errors.New("Symbol without modules.")
+	}
+	quiet := qr.quietZone(opts.QuietZone)
+	cols, rows := len(qr.Canvas[0])+2*quiet, len(qr.Canvas)+2*quiet
+	module := Millimetre
+	if opts.Width > 0 {
+		module = opts.Width / float64(cols)
+	}
+
+	width, height := float64(cols)*module, float64(rows)*module
+	offset := float64(quiet) * module
+	content := qr.pdfContent(offset, offset, module, height)
+	return (&pdfDocument{}).write(w, width, height, []string{content}, false)
+}
+
+// Writes the labels onto as many sheets as needed, filling each one
+// row by row. Every symbol is scaled to the largest size fitting the
+// label with its quiet zone, centred horizontally, with the caption
+// centred below it.
+func WriteLabelsPDF(w io.Writer, sheet LabelSheet, labels []Label) error {
+	if sheet.Columns < 1 || sheet.Rows < 1 {
+		return errors.New("Label sheet without labels.")
+	}
+	if len(labels) == 0 {
+		return errors.New("No labels to print.")
+	}
+
+	perPage := sheet.Columns * sheet.Rows
+	contents, font := []string{}, false
+	for i, label := range labels {
+		if label.Symbol == nil || len(label.Symbol.Canvas) == 0 {
+			return errors.New("Label " + strconv.Itoa(i+1) + " without symbol.")
+		}
+		if i%perPage == 0 {
+			contents = append(contents, "")
+		}
+		cell := i % perPage
+		left := sheet.Left + float64(cell%sheet.Columns)*sheet.PitchX
+		top := sheet.Top + float64(cell/sheet.Columns)*sheet.PitchY
+
+		width := sheet.LabelWidth - 2*sheet.Padding
+		height := sheet.LabelHeight - 2*sheet.Padding
+		if label.Caption != "" {
+			height -= sheet.FontSize * 1.2
+		}
+		qr := label.Symbol
+		quiet := qr.QuietZone()
+		cols, rows := len(qr.Canvas[0])+2*quiet, len(qr.Canvas)+2*quiet
+		module := math.Min(width/float64(cols), height/float64(rows))
+		if module <= 0 {
+			return errors.New("Label too small for symbol " + strconv.Itoa(i+1) + ".")
+		}
+
+		x := left + sheet.Padding + (width-float64(cols)*module)/2 + float64(quiet)*module
+		y := top + sheet.Padding + float64(quiet)*module
+		content := qr.pdfContent(x, y, module, sheet.PageHeight)
+
+		if label.Caption != "" {
+			font = true
+			text, textWidth := pdfText(label.Caption)
+			tx := left + (sheet.LabelWidth-float64(textWidth)*sheet.FontSize/1000)/2
+			ty := sheet.PageHeight - (top + sheet.Padding + float64(rows)*module + sheet.FontSize)
+			content += "BT /F1 " + pdfNumber(sheet.FontSize) + " Tf " + pdfNumber(tx) + " " +
+				pdfNumber(ty) + " Td (" + text + ") Tj ET\n"
+		}
+		contents[len(contents)-1] += content
+	}
+	return (&pdfDocument{}).write(w, sheet.PageWidth, sheet.PageHeight, contents, font)
+}
@@ -0,0 +1,111 @@
+package qrgo
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Checks the cross reference table and returns the inflated content
+// streams of the document.
+func readPDF(t *testing.T, pdf []byte) []string {
+	s := string(pdf)
+	assert.True(t, strings.HasPrefix(s, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(s, "%%EOF\n"))
+
+	start := strings.LastIndex(s, "startxref\n")
+	xref, _ := strconv.Atoi(strings.Fields(s[start+10:])[0])
+	assert.True(t, strings.HasPrefix(s[xref:], "xref\n"))
+	for i, m := range regexp.MustCompile(`(\d{10}) 00000 n`).FindAllStringSubmatch(s[xref:], -1) {
+		offset, _ := strconv.Atoi(m[1])
+		assert.True(t, strings.HasPrefix(s[offset:], strconv.Itoa(i+1)+" 0 obj\n"))
+	}
+
+	contents := []string{}
+	for _, m := range regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllStringSubmatch(s, -1) {
+		z, err := zlib.NewReader(strings.NewReader(m[1]))
+		if assert.Nil(t, err) {
+			data, _ := io.ReadAll(z)
+			contents = append(contents, string(data))
+		}
+	}
+	return contents
+}
+
+func TestPDFText(t *testing.T) {
+	text, width := pdfText("Box (A)\\1")
+	assert.Equal(t, `Box \(A\)\\1`, text)
+	assert.Equal(t, 667+556+500+278+333+667+333+278+556, width)
+	// Doc example
+	text, _ = pdfText("Größe “A” 漢")
+	assert.Equal(t, `Gr\366\337e \223A\224 ?`, text)
+	text, _ = pdfText("5 € … ƒ Ÿ")
+	assert.Equal(t, `5 \200 \205 \203 \237`, text)
+	// C1 controls and codes unassigned in WinAnsiEncoding.
+	text, width = pdfText("\u0080\u0081\u009f\x7f\t")
+	assert.Equal(t, "?????", text)
+	assert.Equal(t, 5*556, width)
+}
+
+func TestWritePDF(t *testing.T) {
+	qr, _ := NewQR("PDF")
+	var buf bytes.Buffer
+	assert.Nil(t, qr.WritePDF(&buf, PDFOptions{Width: 29 * Millimetre}))
+	assert.Contains(t, buf.String(), "/MediaBox [0 0 82.20472 82.20472]")
+
+	contents := readPDF(t, buf.Bytes())
+	if assert.Equal(t, 1, len(contents)) {
+		assert.True(t, strings.HasPrefix(contents[0], "q 2.83465 0 0 -2.83465 11.33858 70.86614 cm\n"))
+		assert.True(t, strings.HasSuffix(contents[0], "h\nf Q\n"))
+		assert.Equal(t, len(outlines(qr.Canvas)), strings.Count(contents[0], " m\n"))
+	}
+
+	buf.Reset()
+	assert.Nil(t, qr.WritePDF(&buf, PDFOptions{QuietZone: -1}))
+	assert.Contains(t, buf.String(), "/MediaBox [0 0 59.52756 59.52756]")
+	assert.NotContains(t, buf.String(), "/Font")
+
+	assert.NotNil(t, (&QR{}).WritePDF(&buf, PDFOptions{}))
+}
+
+func TestWriteLabelsPDF(t *testing.T) {
+	labels := []Label{}
+	for i := 0; i < 31; i++ {
+		qr, _ := NewQRLevel("BOX-"+strconv.Itoa(i), LevelM)
+		labels = append(labels, Label{qr, "Box " + strconv.Itoa(i)})
+	}
+	var buf bytes.Buffer
+	assert.Nil(t, WriteLabelsPDF(&buf, Avery5160, labels))
+	pdf := buf.String()
+	assert.Contains(t, pdf, "/Type /Pages /Kids [")
+	assert.Contains(t, pdf, "/Count 2 >>")
+	assert.Contains(t, pdf, "/MediaBox [0 0 612 792]")
+	assert.Contains(t, pdf, "/BaseFont /Helvetica")
+
+	contents := readPDF(t, buf.Bytes())
+	if assert.Equal(t, 2, len(contents)) {
+		assert.Equal(t, 30, strings.Count(contents[0], " cm\n"))
+		assert.Equal(t, 1, strings.Count(contents[1], " cm\n"))
+		assert.Contains(t, contents[0], "(Box 29) Tj")
+		assert.Contains(t, contents[1], "(Box 30) Tj")
+	}
+
+	// 29 modules with the quiet zone fill the label height, less the
+	// padding and the caption line.
+	module := (72 - 2*4.5 - 7*1.2) / 29
+	assert.Contains(t, contents[0], "q "+pdfNumber(module)+" 0 0 "+pdfNumber(-module)+" ")
+
+	buf.Reset()
+	assert.Nil(t, WriteLabelsPDF(&buf, AveryL7160, []Label{{Symbol: labels[0].Symbol}}))
+	assert.NotContains(t, buf.String(), "/Font")
+
+	assert.NotNil(t, WriteLabelsPDF(&buf, Avery5160, nil))
+	assert.NotNil(t, WriteLabelsPDF(&buf, LabelSheet{}, labels))
+	assert.NotNil(t, WriteLabelsPDF(&buf, Avery5160, []Label{{Caption: "empty"}}))
+}
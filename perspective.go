@@ -0,0 +1,67 @@
+package qrgo
+
+// A projective transform of the plane, applied to homogeneous
+// coordinates as (x', y', w') = m * (x, y, 1).
+type perspective [3][3]float64
+
+// The point (x, y) mapped through the transform.
+func (p perspective) transform(x, y float64) (float64, float64) {
+	w := p[2][0]*x + p[2][1]*y + p[2][2]
+	return (p[0][0]*x + p[0][1]*y + p[0][2]) / w,
+		(p[1][0]*x + p[1][1]*y + p[1][2]) / w
+}
+
+// The transform applying q first and p second.
+func (p perspective) times(q perspective) perspective {
+	var r perspective
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				r[i][j] += p[i][k] * q[k][j]
+			}
+		}
+	}
+	return r
+}
+
+// The adjugate matrix, which is the inverse transform up to
+// a scale factor that does not matter in homogeneous coordinates.
+func (p perspective) adjoint() perspective {
+	return perspective{
+		{p[1][1]*p[2][2] - p[1][2]*p[2][1], p[0][2]*p[2][1] - p[0][1]*p[2][2], p[0][1]*p[1][2] - p[0][2]*p[1][1]},
+		{p[1][2]*p[2][0] - p[1][0]*p[2][2], p[0][0]*p[2][2] - p[0][2]*p[2][0], p[0][2]*p[1][0] - p[0][0]*p[1][2]},
+		{p[1][0]*p[2][1] - p[1][1]*p[2][0], p[0][1]*p[2][0] - p[0][0]*p[2][1], p[0][0]*p[1][1] - p[0][1]*p[1][0]},
+	}
+}
+
+// Maps the unit square (0, 0), (1, 0), (1, 1), (0, 1) onto the
+// quadrilateral q given in the same order.
+func squareToQuad(q [4][2]float64) perspective {
+	x0, y0, x1, y1 := q[0][0], q[0][1], q[1][0], q[1][1]
+	x2, y2, x3, y3 := q[2][0], q[2][1], q[3][0], q[3][1]
+	dx3, dy3 := x0-x1+x2-x3, y0-y1+y2-y3
+	if dx3 == 0 && dy3 == 0 {
+		// A parallelogram only needs an affine transform.
+		return perspective{
+			{x1 - x0, x3 - x0, x0},
+			{y1 - y0, y3 - y0, y0},
+			{0, 0, 1},
+		}
+	}
+
+	dx1, dx2, dy1, dy2 := x1-x2, x3-x2, y1-y2, y3-y2
+	denom := dx1*dy2 - dx2*dy1
+	g := (dx3*dy2 - dx2*dy3) / denom
+	h := (dx1*dy3 - dx3*dy1) / denom
+	return perspective{
+		{x1 - x0 + g*x1, x3 - x0 + h*x3, x0},
+		{y1 - y0 + g*y1, y3 - y0 + h*y3, y0},
+		{g, h, 1},
+	}
+}
+
+// Maps the quadrilateral from onto the quadrilateral to,
+// corner by corner.
+func quadToQuad(from, to [4][2]float64) perspective {
+	return squareToQuad(to).times(squareToQuad(from).adjoint())
+}
@@ -0,0 +1,41 @@
+package qrgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSquareToQuad(t *testing.T) {
+	quads := [][4][2]float64{
+		{{10, 20}, {110, 20}, {110, 120}, {10, 120}},
+		{{10, 20}, {110, 40}, {130, 140}, {30, 120}},
+		{{50, 40}, {330, 90}, {290, 330}, {70, 280}},
+	}
+	square := [4][2]float64{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	for _, q := range quads {
+		p := squareToQuad(q)
+		for i, s := range square {
+			x, y := p.transform(s[0], s[1])
+			assert.InDelta(t, q[i][0], x, 1e-9)
+			assert.InDelta(t, q[i][1], y, 1e-9)
+		}
+	}
+}
+
+func TestQuadToQuad(t *testing.T) {
+	from := [4][2]float64{{3.5, 3.5}, {21.5, 3.5}, {18.5, 18.5}, {3.5, 21.5}}
+	to := [4][2]float64{{50, 40}, {330, 90}, {290, 330}, {70, 280}}
+	p := quadToQuad(from, to)
+	for i := range from {
+		x, y := p.transform(from[i][0], from[i][1])
+		assert.InDelta(t, to[i][0], x, 1e-9)
+		assert.InDelta(t, to[i][1], y, 1e-9)
+	}
+
+	// The adjoint maps back.
+	x, y := p.transform(10, 12)
+	x, y = p.adjoint().transform(x, y)
+	assert.InDelta(t, 10, x, 1e-9)
+	assert.InDelta(t, 12, y, 1e-9)
+}
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"math"
-	"regexp"
 	"strconv"
 	"strings"
 )
@@ -13,11 +12,28 @@ import (
 // containing all preliminary steps the lead to the
 // result.
 type QR struct {
+	Type    int
 	Data    string
-	Length  int
-	Mode    int
+	Length  int // Number of chars over all segments.
+	Mode    int // Mode of the first segment.
+	ECI     int // Designator of the ECI header, 0 for none.
+	Level   int
 	Version int
 	Modules int
+	Width   int // Number of columns, Modules for square symbols.
+
+	// Position in a structured append sequence of Total symbols
+	// sharing the parity of the whole data. Total is 0 for
+	// standalone symbols.
+	Sequence int
+	Total    int
+	Parity   byte
+
+	// FNC1 marks data following an industry standard, either GS1
+	// in the first position or the one given by the application
+	// indicator in the second position.
+	FNC1        int
+	Application int
 
 	Errors int
 	Block1 int
@@ -25,6 +41,10 @@ type QR struct {
 	Block2 int
 	Words2 int
 
+	segments  []segment
+	fixedMask bool // Mask is given instead of chosen by penalty.
+	fixedECI  bool // ECI is given instead of chosen by the data.
+
 	Encoding    []byte
 	Correction  []byte
 	Interleaved string
@@ -39,17 +59,38 @@ type Cell struct {
 
 type mask func(row, col int) bool
 
+// Symbol types. Micro QR versions M1 to M4 are numbered 1 to 4,
+// the rMQR sizes R7x43 to R17x139 1 to 32.
+const (
+	TypeQR = iota
+	TypeMicro
+	TypeRMQR
+)
+
+// Error correction levels. Each level trades capacity for the
+// share of codewords that can be restored when the symbol is
+// damaged: roughly 7%, 15%, 25% and 30% respectively.
 const (
-	regexNumeric = "^.[0-9]*$"                   // [0,9]
-	regexAlpha   = "^.[0-9A-Z /.:%+\\$\\-\\*]*$" // [0,9] | [A,Z] | {/.:%+$-*}
+	LevelL = iota
+	LevelM
+	LevelQ
+	LevelH
+)
 
+const (
 	numeric  = 1
 	alpha    = 2
 	byteMode = 4
+	kanji    = 8
 
 	indNumeric = "0001"
 	indAlpha   = "0010"
 	indBytes   = "0100"
+	indKanji   = "1000"
+	indECI     = "0111"
+	indAppend  = "0011"
+	indFNC1    = "0101"
+	indAIM     = "1001"
 
 	versions = 40
 
@@ -58,23 +99,92 @@ const (
 )
 
 var (
-	maxCharsNumeric = []int{
-		41, 77, 127, 187, 255, 322, 370, 461, 552, 652, 772, 883, 1022,
-		1101, 1250, 1408, 1548, 1725, 1903, 2061, 2232, 2409, 2620, 2812,
-		3057, 3283, 3517, 3669, 3909, 4158, 4417, 4686, 4965, 5253, 5529,
-		5836, 6153, 6479, 6743, 7089}
-
-	maxCharsAlpha = []int{
-		25, 47, 77, 114, 154, 195, 224, 279, 335, 395, 468, 535, 619,
-		667, 758, 854, 938, 1046, 1153, 1249, 1352, 1460, 1588, 1704,
-		1853, 1990, 2132, 2223, 2369, 2520, 2677, 2840, 3009, 3183,
-		3351, 3537, 3729, 3927, 4087, 4296}
-
-	maxCharsBytes = []int{
-		17, 32, 53, 78, 106, 134, 154, 192, 230, 271, 321, 367, 425, 458,
-		520, 586, 644, 718, 792, 858, 929, 779, 1091, 1171, 1273, 1367,
-		1465, 1528, 1628, 1732, 1840, 1952, 2068, 2188, 2303, 2431, 2563,
-		2699, 2809, 2953}
+	maxCharsNumeric = [][]int{
+		LevelL: {
+			41, 77, 127, 187, 255, 322, 370, 461, 552, 652, 772, 883, 1022, 1101,
+			1250, 1408, 1548, 1725, 1903, 2061, 2232, 2409, 2620, 2812, 3057,
+			3283, 3517, 3669, 3909, 4158, 4417, 4686, 4965, 5253, 5529, 5836,
+			6153, 6479, 6743, 7089},
+		LevelM: {
+			34, 63, 101, 149, 202, 255, 293, 365, 432, 513, 604, 691, 796, 871,
+			991, 1082, 1212, 1346, 1500, 1600, 1708, 1872, 2059, 2188, 2395,
+			2544, 2701, 2857, 3035, 3289, 3486, 3693, 3909, 4134, 4343, 4588,
+			4775, 5039, 5313, 5596},
+		LevelQ: {
+			27, 48, 77, 111, 144, 178, 207, 259, 312, 364, 427, 489, 580, 621,
+			703, 775, 876, 948, 1063, 1159, 1224, 1358, 1468, 1588, 1718, 1804,
+			1933, 2085, 2181, 2358, 2473, 2670, 2805, 2949, 3081, 3244, 3417,
+			3599, 3791, 3993},
+		LevelH: {
+			17, 34, 58, 82, 106, 139, 154, 202, 235, 288, 331, 374, 427, 468,
+			530, 602, 674, 746, 813, 919, 969, 1056, 1108, 1228, 1286, 1425,
+			1501, 1581, 1677, 1782, 1897, 2022, 2157, 2301, 2361, 2524, 2625,
+			2735, 2927, 3057},
+	}
+
+	maxCharsAlpha = [][]int{
+		LevelL: {
+			25, 47, 77, 114, 154, 195, 224, 279, 335, 395, 468, 535, 619, 667,
+			758, 854, 938, 1046, 1153, 1249, 1352, 1460, 1588, 1704, 1853, 1990,
+			2132, 2223, 2369, 2520, 2677, 2840, 3009, 3183, 3351, 3537, 3729,
+			3927, 4087, 4296},
+		LevelM: {
+			20, 38, 61, 90, 122, 154, 178, 221, 262, 311, 366, 419, 483, 528,
+			600, 656, 734, 816, 909, 970, 1035, 1134, 1248, 1326, 1451, 1542,
+			1637, 1732, 1839, 1994, 2113, 2238, 2369, 2506, 2632, 2780, 2894,
+			3054, 3220, 3391},
+		LevelQ: {
+			16, 29, 47, 67, 87, 108, 125, 157, 189, 221, 259, 296, 352, 376, 426,
+			470, 531, 574, 644, 702, 742, 823, 890, 963, 1041, 1094, 1172, 1263,
+			1322, 1429, 1499, 1618, 1700, 1787, 1867, 1966, 2071, 2181, 2298,
+			2420},
+		LevelH: {
+			10, 20, 35, 50, 64, 84, 93, 122, 143, 174, 200, 227, 259, 283, 321,
+			365, 408, 452, 493, 557, 587, 640, 672, 744, 779, 864, 910, 958,
+			1016, 1080, 1150, 1226, 1307, 1394, 1431, 1530, 1591, 1658, 1774,
+			1852},
+	}
+
+	maxCharsBytes = [][]int{
+		LevelL: {
+			17, 32, 53, 78, 106, 134, 154, 192, 230, 271, 321, 367, 425, 458,
+			520, 586, 644, 718, 792, 858, 929, 1003, 1091, 1171, 1273, 1367,
+			1465, 1528, 1628, 1732, 1840, 1952, 2068, 2188, 2303, 2431, 2563,
+			2699, 2809, 2953},
+		LevelM: {
+			14, 26, 42, 62, 84, 106, 122, 152, 180, 213, 251, 287, 331, 362, 412,
+			450, 504, 560, 624, 666, 711, 779, 857, 911, 997, 1059, 1125, 1190,
+			1264, 1370, 1452, 1538, 1628, 1722, 1809, 1911, 1989, 2099, 2213,
+			2331},
+		LevelQ: {
+			11, 20, 32, 46, 60, 74, 86, 108, 130, 151, 177, 203, 241, 258, 292,
+			322, 364, 394, 442, 482, 509, 565, 611, 661, 715, 751, 805, 868, 908,
+			982, 1030, 1112, 1168, 1228, 1283, 1351, 1423, 1499, 1579, 1663},
+		LevelH: {
+			7, 14, 24, 34, 44, 58, 64, 84, 98, 119, 137, 155, 177, 194, 220, 250,
+			280, 310, 338, 382, 403, 439, 461, 511, 535, 593, 625, 658, 698, 742,
+			790, 842, 898, 958, 983, 1051, 1093, 1139, 1219, 1273},
+	}
+
+	maxCharsKanji = [][]int{
+		LevelL: {
+			10, 20, 32, 48, 65, 82, 95, 118, 141, 167, 198, 226, 262, 282, 320,
+			361, 397, 442, 488, 528, 572, 618, 672, 721, 784, 842, 902, 940,
+			1002, 1066, 1132, 1201, 1273, 1347, 1417, 1496, 1577, 1661, 1729,
+			1817},
+		LevelM: {
+			8, 16, 26, 38, 52, 65, 75, 93, 111, 131, 155, 177, 204, 223, 254,
+			277, 310, 345, 384, 410, 438, 480, 528, 561, 614, 652, 692, 732, 778,
+			843, 894, 947, 1002, 1060, 1113, 1176, 1224, 1292, 1362, 1435},
+		LevelQ: {
+			7, 12, 20, 28, 37, 45, 53, 66, 80, 93, 109, 125, 149, 159, 180, 198,
+			224, 243, 272, 297, 314, 348, 376, 407, 440, 462, 496, 534, 559, 604,
+			634, 684, 719, 756, 790, 832, 876, 923, 972, 1024},
+		LevelH: {
+			4, 8, 15, 21, 27, 36, 39, 52, 60, 74, 85, 96, 109, 120, 136, 154,
+			173, 191, 208, 235, 248, 270, 284, 315, 330, 365, 385, 405, 430, 457,
+			486, 518, 553, 590, 605, 647, 673, 701, 750, 784},
+	}
 
 	alphaTable = map[rune]int{
 		'0': 0, '1': 1, '2': 2, '3': 3, '4': 4, '5': 5, '6': 6, '7': 7, '8': 8,
@@ -91,37 +201,101 @@ var (
 	// 5: # Blocks in Group2
 	// 6: # Codewords in Blocks of Group2
 	// 7: # Remainder Bits
-	blockInfo = map[int][7]int{
-		1: {19, 7, 1, 19, 0, 0, 0}, 2: {34, 10, 1, 34, 0, 0, 7},
-		3: {55, 15, 1, 55, 0, 0, 7}, 4: {80, 20, 1, 80, 0, 0, 7},
-		5: {108, 26, 1, 108, 0, 0, 7}, 6: {136, 18, 2, 68, 0, 0, 7},
-		7: {156, 20, 2, 78, 0, 0, 0}, 8: {194, 24, 2, 97, 0, 0, 0},
-		9: {232, 30, 2, 116, 0, 0, 0}, 10: {274, 18, 2, 68, 2, 69, 0},
-		11: {324, 20, 4, 81, 0, 0, 0}, 12: {370, 24, 2, 92, 2, 93, 0},
-		13: {428, 26, 4, 107, 0, 0, 0}, 14: {461, 30, 3, 115, 1, 116, 0}}
-
-	alignmentPatterns = map[int][]int{
-		2: {18, 18}, 3: {22, 22}, 4: {26, 26}, 5: {30, 30}, 6: {34, 34},
-		7:  {6, 22, 22, 6, 22, 22, 22, 38, 38, 22, 38, 38},
-		8:  {6, 24, 24, 6, 24, 24, 24, 42, 42, 24, 42, 42},
-		9:  {6, 26, 26, 6, 26, 26, 26, 46, 46, 26, 46, 46},
-		10: {6, 28, 28, 6, 28, 28, 28, 50, 50, 28, 50, 50},
-		11: {6, 30, 30, 6, 30, 30, 30, 54, 54, 30, 54, 54},
-		12: {6, 32, 32, 6, 32, 32, 32, 58, 58, 32, 58, 58}}
+	blockInfo = []map[int][7]int{
+		LevelL: {
+			1: {19, 7, 1, 19, 0, 0, 0}, 2: {34, 10, 1, 34, 0, 0, 7},
+			3: {55, 15, 1, 55, 0, 0, 7}, 4: {80, 20, 1, 80, 0, 0, 7},
+			5: {108, 26, 1, 108, 0, 0, 7}, 6: {136, 18, 2, 68, 0, 0, 7},
+			7: {156, 20, 2, 78, 0, 0, 0}, 8: {194, 24, 2, 97, 0, 0, 0},
+			9: {232, 30, 2, 116, 0, 0, 0}, 10: {274, 18, 2, 68, 2, 69, 0},
+			11: {324, 20, 4, 81, 0, 0, 0}, 12: {370, 24, 2, 92, 2, 93, 0},
+			13: {428, 26, 4, 107, 0, 0, 0}, 14: {461, 30, 3, 115, 1, 116, 3},
+			15: {523, 22, 5, 87, 1, 88, 3}, 16: {589, 24, 5, 98, 1, 99, 3},
+			17: {647, 28, 1, 107, 5, 108, 3}, 18: {721, 30, 5, 120, 1, 121, 3},
+			19: {795, 28, 3, 113, 4, 114, 3}, 20: {861, 28, 3, 107, 5, 108, 3},
+			21: {932, 28, 4, 116, 4, 117, 4}, 22: {1006, 28, 2, 111, 7, 112, 4},
+			23: {1094, 30, 4, 121, 5, 122, 4}, 24: {1174, 30, 6, 117, 4, 118, 4},
+			25: {1276, 26, 8, 106, 4, 107, 4}, 26: {1370, 28, 10, 114, 2, 115, 4},
+			27: {1468, 30, 8, 122, 4, 123, 4}, 28: {1531, 30, 3, 117, 10, 118, 3},
+			29: {1631, 30, 7, 116, 7, 117, 3}, 30: {1735, 30, 5, 115, 10, 116, 3},
+			31: {1843, 30, 13, 115, 3, 116, 3}, 32: {1955, 30, 17, 115, 0, 0, 3},
+			33: {2071, 30, 17, 115, 1, 116, 3}, 34: {2191, 30, 13, 115, 6, 116, 3},
+			35: {2306, 30, 12, 121, 7, 122, 0}, 36: {2434, 30, 6, 121, 14, 122, 0},
+			37: {2566, 30, 17, 122, 4, 123, 0}, 38: {2702, 30, 4, 122, 18, 123, 0},
+			39: {2812, 30, 20, 117, 4, 118, 0}, 40: {2956, 30, 19, 118, 6, 119, 0},
+		},
+		LevelM: {
+			1: {16, 10, 1, 16, 0, 0, 0}, 2: {28, 16, 1, 28, 0, 0, 7},
+			3: {44, 26, 1, 44, 0, 0, 7}, 4: {64, 18, 2, 32, 0, 0, 7},
+			5: {86, 24, 2, 43, 0, 0, 7}, 6: {108, 16, 4, 27, 0, 0, 7},
+			7: {124, 18, 4, 31, 0, 0, 0}, 8: {154, 22, 2, 38, 2, 39, 0},
+			9: {182, 22, 3, 36, 2, 37, 0}, 10: {216, 26, 4, 43, 1, 44, 0},
+			11: {254, 30, 1, 50, 4, 51, 0}, 12: {290, 22, 6, 36, 2, 37, 0},
+			13: {334, 22, 8, 37, 1, 38, 0}, 14: {365, 24, 4, 40, 5, 41, 3},
+			15: {415, 24, 5, 41, 5, 42, 3}, 16: {453, 28, 7, 45, 3, 46, 3},
+			17: {507, 28, 10, 46, 1, 47, 3}, 18: {563, 26, 9, 43, 4, 44, 3},
+			19: {627, 26, 3, 44, 11, 45, 3}, 20: {669, 26, 3, 41, 13, 42, 3},
+			21: {714, 26, 17, 42, 0, 0, 4}, 22: {782, 28, 17, 46, 0, 0, 4},
+			23: {860, 28, 4, 47, 14, 48, 4}, 24: {914, 28, 6, 45, 14, 46, 4},
+			25: {1000, 28, 8, 47, 13, 48, 4}, 26: {1062, 28, 19, 46, 4, 47, 4},
+			27: {1128, 28, 22, 45, 3, 46, 4}, 28: {1193, 28, 3, 45, 23, 46, 3},
+			29: {1267, 28, 21, 45, 7, 46, 3}, 30: {1373, 28, 19, 47, 10, 48, 3},
+			31: {1455, 28, 2, 46, 29, 47, 3}, 32: {1541, 28, 10, 46, 23, 47, 3},
+			33: {1631, 28, 14, 46, 21, 47, 3}, 34: {1725, 28, 14, 46, 23, 47, 3},
+			35: {1812, 28, 12, 47, 26, 48, 0}, 36: {1914, 28, 6, 47, 34, 48, 0},
+			37: {1992, 28, 29, 46, 14, 47, 0}, 38: {2102, 28, 13, 46, 32, 47, 0},
+			39: {2216, 28, 40, 47, 7, 48, 0}, 40: {2334, 28, 18, 47, 31, 48, 0},
+		},
+		LevelQ: {
+			1: {13, 13, 1, 13, 0, 0, 0}, 2: {22, 22, 1, 22, 0, 0, 7},
+			3: {34, 18, 2, 17, 0, 0, 7}, 4: {48, 26, 2, 24, 0, 0, 7},
+			5: {62, 18, 2, 15, 2, 16, 7}, 6: {76, 24, 4, 19, 0, 0, 7},
+			7: {88, 18, 2, 14, 4, 15, 0}, 8: {110, 22, 4, 18, 2, 19, 0},
+			9: {132, 20, 4, 16, 4, 17, 0}, 10: {154, 24, 6, 19, 2, 20, 0},
+			11: {180, 28, 4, 22, 4, 23, 0}, 12: {206, 26, 4, 20, 6, 21, 0},
+			13: {244, 24, 8, 20, 4, 21, 0}, 14: {261, 20, 11, 16, 5, 17, 3},
+			15: {295, 30, 5, 24, 7, 25, 3}, 16: {325, 24, 15, 19, 2, 20, 3},
+			17: {367, 28, 1, 22, 15, 23, 3}, 18: {397, 28, 17, 22, 1, 23, 3},
+			19: {445, 26, 17, 21, 4, 22, 3}, 20: {485, 30, 15, 24, 5, 25, 3},
+			21: {512, 28, 17, 22, 6, 23, 4}, 22: {568, 30, 7, 24, 16, 25, 4},
+			23: {614, 30, 11, 24, 14, 25, 4}, 24: {664, 30, 11, 24, 16, 25, 4},
+			25: {718, 30, 7, 24, 22, 25, 4}, 26: {754, 28, 28, 22, 6, 23, 4},
+			27: {808, 30, 8, 23, 26, 24, 4}, 28: {871, 30, 4, 24, 31, 25, 3},
+			29: {911, 30, 1, 23, 37, 24, 3}, 30: {985, 30, 15, 24, 25, 25, 3},
+			31: {1033, 30, 42, 24, 1, 25, 3}, 32: {1115, 30, 10, 24, 35, 25, 3},
+			33: {1171, 30, 29, 24, 19, 25, 3}, 34: {1231, 30, 44, 24, 7, 25, 3},
+			35: {1286, 30, 39, 24, 14, 25, 0}, 36: {1354, 30, 46, 24, 10, 25, 0},
+			37: {1426, 30, 49, 24, 10, 25, 0}, 38: {1502, 30, 48, 24, 14, 25, 0},
+			39: {1582, 30, 43, 24, 22, 25, 0}, 40: {1666, 30, 34, 24, 34, 25, 0},
+		},
+		LevelH: {
+			1: {9, 17, 1, 9, 0, 0, 0}, 2: {16, 28, 1, 16, 0, 0, 7},
+			3: {26, 22, 2, 13, 0, 0, 7}, 4: {36, 16, 4, 9, 0, 0, 7},
+			5: {46, 22, 2, 11, 2, 12, 7}, 6: {60, 28, 4, 15, 0, 0, 7},
+			7: {66, 26, 4, 13, 1, 14, 0}, 8: {86, 26, 4, 14, 2, 15, 0},
+			9: {100, 24, 4, 12, 4, 13, 0}, 10: {122, 28, 6, 15, 2, 16, 0},
+			11: {140, 24, 3, 12, 8, 13, 0}, 12: {158, 28, 7, 14, 4, 15, 0},
+			13: {180, 22, 12, 11, 4, 12, 0}, 14: {197, 24, 11, 12, 5, 13, 3},
+			15: {223, 24, 11, 12, 7, 13, 3}, 16: {253, 30, 3, 15, 13, 16, 3},
+			17: {283, 28, 2, 14, 17, 15, 3}, 18: {313, 28, 2, 14, 19, 15, 3},
+			19: {341, 26, 9, 13, 16, 14, 3}, 20: {385, 28, 15, 15, 10, 16, 3},
+			21: {406, 30, 19, 16, 6, 17, 4}, 22: {442, 24, 34, 13, 0, 0, 4},
+			23: {464, 30, 16, 15, 14, 16, 4}, 24: {514, 30, 30, 16, 2, 17, 4},
+			25: {538, 30, 22, 15, 13, 16, 4}, 26: {596, 30, 33, 16, 4, 17, 4},
+			27: {628, 30, 12, 15, 28, 16, 4}, 28: {661, 30, 11, 15, 31, 16, 3},
+			29: {701, 30, 19, 15, 26, 16, 3}, 30: {745, 30, 23, 15, 25, 16, 3},
+			31: {793, 30, 23, 15, 28, 16, 3}, 32: {845, 30, 19, 15, 35, 16, 3},
+			33: {901, 30, 11, 15, 46, 16, 3}, 34: {961, 30, 59, 16, 1, 17, 3},
+			35: {986, 30, 22, 15, 41, 16, 0}, 36: {1054, 30, 2, 15, 64, 16, 0},
+			37: {1096, 30, 24, 15, 46, 16, 0}, 38: {1142, 30, 42, 15, 32, 16, 0},
+			39: {1222, 30, 10, 15, 67, 16, 0}, 40: {1276, 30, 20, 15, 61, 16, 0},
+		},
+	}
 
 	terminatorPads   = []string{"11101100", "00010001"}
 	penaltySequences = []string{"10111010000", "00001011101"}
 
 	masks = []mask{mask0, mask1, mask2, mask3, mask4, mask5, mask6, mask7}
-
-	formatInformationStrings = []string{
-		"111011111000100", "111001011110011", "111110110101010",
-		"111100010011101", "110011000101111", "110001100011000",
-		"110110001000001", "110100101110110"}
-
-	versionInformationStrings = []string{
-		"000111110010010100", "001000010110111100", "001001101010011001",
-		"001010010011010011", "001011101111110110", "001100011101100010"}
 )
 
 // Canonical integer max function.
@@ -213,81 +387,111 @@ func binarySearch(array []int, value int) int {
 	return low
 }
 
-func (qr *QR) mode() {
-	rNumeric, _ := regexp.Compile(regexNumeric)
-	rAlpha, _ := regexp.Compile(regexAlpha)
+// Splits the data into segments and chooses the smallest version
+// holding them. The segments are split once for every range of
+// versions sharing the widths of the count indicators.
+func (qr *QR) version(split func(version int) []segment) {
+	qr.versionBetween(split, 1, versions)
+}
 
-	if rNumeric.MatchString(qr.Data) {
-		qr.Mode = numeric
-	} else if rAlpha.MatchString(qr.Data) {
-		qr.Mode = alpha
-	} else {
-		qr.Mode = byteMode
+// Like version, but only chooses from the versions min to max. The
+// version is set beyond max if none of them holds the data.
+func (qr *QR) versionBetween(split func(version int) []segment, min, max int) {
+	for _, r := range [][2]int{{1, 9}, {10, 26}, {27, versions}} {
+		if r[1] < min || r[0] > max {
+			continue
+		}
+		segments := split(r[0])
+		bits := qr.headerBits() + segmentBits(segments, r[0])
+		for v := r[0]; v <= r[1]; v++ {
+			if v < min || v > max {
+				continue
+			}
+			if bits <= blockInfo[qr.Level][v][0]*8 {
+				qr.Version, qr.segments = v, segments
+				qr.Mode, qr.Length = segments[0].mode, 0
+				for _, s := range segments {
+					qr.Length += s.length()
+				}
+				return
+			}
+		}
 	}
+	qr.Version = max + 1
 }
 
-func (qr *QR) version() {
-	if qr.Mode == numeric {
-		qr.Version = binarySearch(maxCharsNumeric, qr.Length) + 1
-	} else if qr.Mode == alpha {
-		qr.Version = binarySearch(maxCharsAlpha, qr.Length) + 1
-	} else {
-		qr.Version = binarySearch(maxCharsBytes, qr.Length) + 1
-	}
+// Number of bits of the structured append, ECI and FNC1 headers
+// preceding the segments.
+func (qr *QR) headerBits() int {
+	return appendBits(qr.Total) + eciBits(qr.ECI) + fnc1Bits(qr.FNC1)
 }
 
 // The count indicator follows the mode indicator in the
 // encoding. The indicator's length differs in connection
 // to the given length, mode and version of the data string.
+func indCount(length, mode, version int) string {
+	count := strconv.FormatInt(int64(length), 2)
+	return padLeft(count, countBits(mode, version))
+}
+
+// Number of bits of the count indicator.
 //
 //		Version [1, 9]:
-//			Numeric:	10 byteMode
-//			Alpha: 		9 byteMode
-//			Bytes:		8 byteMode
+//			Numeric:	10 bits
+//			Alpha: 		9 bits
+//			Bytes:		8 bits
+//			Kanji:		8 bits
 //
 //		Version [10, 26]:
-//			Numeric:	12 byteMode
-//			Alpha:		11 byteMode
-//			Bytes:		16 byteMode
+//			Numeric:	12 bits
+//			Alpha:		11 bits
+//			Bytes:		16 bits
+//			Kanji:		10 bits
 //
-//		Version [26, 40]:
-//			Numeric:	14 byteMode
-//			Alpha:		13 byteMode
-//			Bytes:		16 byteMode
+//		Version [27, 40]:
+//			Numeric:	14 bits
+//			Alpha:		13 bits
+//			Bytes:		16 bits
+//			Kanji:		12 bits
 //
-func indCount(length, mode, version int) string {
-	count := strconv.FormatInt(int64(length), 2)
+func countBits(mode, version int) int {
 	if version >= 1 && version <= 9 {
 		if mode == numeric {
-			return padLeft(count, 10)
+			return 10
 		} else if mode == alpha {
-			return padLeft(count, 9)
+			return 9
+		} else if mode == kanji {
+			return 8
 		} else {
-			return padLeft(count, 8)
+			return 8
 		}
 	} else if version >= 10 && version <= 26 {
 		if mode == numeric {
-			return padLeft(count, 12)
+			return 12
 		} else if mode == alpha {
-			return padLeft(count, 11)
+			return 11
+		} else if mode == kanji {
+			return 10
 		} else {
-			return padLeft(count, 16)
+			return 16
 		}
 	} else {
 		if mode == numeric {
-			return padLeft(count, 14)
+			return 14
 		} else if mode == alpha {
-			return padLeft(count, 13)
+			return 13
+		} else if mode == kanji {
+			return 12
 		} else {
-			return padLeft(count, 16)
+			return 16
 		}
 	}
 }
 
 // The numeric encoding converts every three-digit number
-// in the data string into its (unsigned) binary representation.
+// in the data string into its 10-bit binary representation.
 // Hanging numbers at the end are equally turned into binary,
-// whereas zeros in front of a number are ignored.
+// using 7 bits for two digits and 4 bits for a single one.
 //
 //		8675309:
 //			867 -> 1101100011
@@ -297,9 +501,10 @@ func indCount(length, mode, version int) string {
 func encNumeric(data string) string {
 	i, encoding := 0, ""
 	for ; i <= len(data)-3; i += 3 {
-		encoding += stringToBinary(data[i : i+3])
+		encoding += padLeft(stringToBinary(data[i:i+3]), 10)
 	}
-	return encoding + stringToBinary(data[i:])
+	tail := data[i:] // Possible hanging digits.
+	return encoding + padLeft(stringToBinary(tail), []int{0, 4, 7}[len(tail)])
 }
 
 // The alphanumeric encoding takes groups of two chars,
@@ -330,31 +535,64 @@ func encAlpha(data string) string {
 	}
 }
 
-// The byte encoding simply turns every char in the data
-// string into its ASCII 8-bit binary representation.
+// The byte encoding simply turns every byte of the data
+// string into its 8-bit binary representation. Chars beyond
+// ASCII take the several bytes of their UTF-8 encoding.
 //
 //		H -> 0x48 -> 01001000
-//		e -> 0x65 -> 01100101
+//		é -> 0xC3 0xA9 -> 11000011 10101001
 //
 func encBytes(data string) string {
+	encoding := ""
+	for i := 0; i < len(data); i++ {
+		encoding += padLeft(strconv.FormatInt(int64(data[i]), 2), 8)
+	}
+	return encoding
+}
+
+// The Kanji encoding converts every char into its double byte
+// Shift JIS code. 0x8140 is subtracted from codes up to 0x9FFC and
+// 0xC140 from those above. The high byte of the result is multiplied
+// with 0xC0 and the low byte added, which fits into 13 bits.
+//
+//		点 -> 0x935F:
+//			0x935F - 0x8140 = 0x121F
+//			0x12 * 0xC0 + 0x1F = 0xD9F = 0110110011111
+//
+func encKanji(data string) string {
 	encoding := ""
 	for _, c := range data {
-		ascii := int64(c)
-		encoding += padLeft(strconv.FormatInt(ascii, 2), 8)
+		code, _ := toShiftJIS(c)
+		if code <= 0x9ffc {
+			code -= 0x8140
+		} else {
+			code -= 0xc140
+		}
+		num := int64((code>>8)*0xc0 + code&0xff)
+		encoding += padLeft(strconv.FormatInt(num, 2), 13)
 	}
 	return encoding
 }
 
-func terminator(encoding string, version int) []byte {
-	length := len(encoding)
-	blocks := blockInfo[version][0]
+func terminator(encoding string, level, version int) []byte {
+	return padCodewords(encoding, blockInfo[level][version][0], 4)
+}
 
+// Fills the encoding up to the given number of codewords.
+func padCodewords(encoding string, blocks, zeros int) []byte {
+	length := len(encoding)
 	if (blocks*8)-length == 0 {
 		return encodingToByteArray(encoding)
 	}
 
-	rest := 8 - (length % 8)
-	padding := padRight(encoding, length+rest)
+	// Up to zeros zero bits terminate the data, followed by zeros
+	// up to the next byte boundary.
+	end := length + zeros
+	if end > blocks*8 {
+		end = blocks * 8
+	}
+	rest := (8 - (end % 8)) % 8
+	padding := padRight(encoding, end+rest)
 
 	numPads := blocks - len(padding)/8
 	for i := 0; i < numPads; i++ {
@@ -543,12 +781,19 @@ func drawSeperator(canvas [][]*Cell, row, col, dr, dc int) {
 }
 
 // Every QR-Code that is not of version 1, has one or more
-// alignmentPatterns. The positions of those patterns can
-// be found in the alignmentPattern table.
+// alignment patterns. Their centres are derived from the
+// version in alignmentPositions.
 func (qr *QR) placeAlignmentPatterns() {
-	patterns := alignmentPatterns[qr.Version]
-	for i := 0; i < len(patterns)-1; i += 2 {
-		drawPattern(qr.Canvas, patterns[i]-2, patterns[i+1]-2, 5)
+	patterns := alignmentPositions(qr.Version)
+	last := len(patterns) - 1
+	for i, row := range patterns {
+		for j, col := range patterns {
+			// Skip the corners occupied by finder patterns.
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+			drawPattern(qr.Canvas, row-2, col-2, 5)
+		}
 	}
 }
 
@@ -576,18 +821,24 @@ func (qr *QR) placeAlignmentPatterns() {
 //		1111111
 //
 func (qr *QR) drawTimingPattern() {
-	length := qr.Modules - 14
-	for i := 6; i < 6+length; i++ {
+	drawTiming(qr.Canvas, 6, 6, qr.Modules-8)
+}
+
+// Draws the horizontal timing pattern along row line and the vertical
+// one along column line, from module from up to but excluding to.
+// Modules of even index are dark.
+func drawTiming(canvas [][]*Cell, line, from, to int) {
+	for i := from; i < to; i++ {
 		if i%2 == 0 {
-			qr.Canvas[6][i].color = 1
-			qr.Canvas[i][6].color = 1
+			canvas[line][i].color = 1
+			canvas[i][line].color = 1
 		} else {
-			qr.Canvas[6][i].color = 0
-			qr.Canvas[i][6].color = 0
+			canvas[line][i].color = 0
+			canvas[i][line].color = 0
 		}
 
-		qr.Canvas[6][i].data = false
-		qr.Canvas[i][6].data = false
+		canvas[line][i].data = false
+		canvas[i][line].data = false
 	}
 }
 
@@ -630,52 +881,60 @@ func (qr *QR) reserveVersionInformationData() {
 	}
 }
 
-func (qr *QR) drawDataBits() {
-	i, up := 0, true
-	for c := qr.Modules - 1; c > 0; c -= 2 {
-		if c == 6 {
+// Walks the data modules of the canvas in placement order: two
+// columns at a time from right to left, alternating upwards and
+// downwards and skipping the column of the vertical timing pattern.
+// In Micro QR symbols that is the leftmost column, which the walk
+// never reaches. rMQR symbols skip their rightmost column instead,
+// which holds only function patterns, so that the walk starts one
+// column further left and ends in the leftmost one.
+func walkDataModules(canvas [][]*Cell, timing int, fn func(row, col int)) {
+	modules, up := len(canvas), true
+	for c := len(canvas[0]) - 1; c > 0; c -= 2 {
+		if c == timing {
 			c++
-		} else {
+			continue
+		}
+		for i := 0; i < modules; i++ {
+			r := i
 			if up {
-				for r := qr.Modules - 1; r >= 0; r-- {
-					if qr.Canvas[r][c].data {
-						wb, _ := strconv.Atoi(string(qr.Interleaved[i]))
-						qr.Canvas[r][c].color = wb
-						i++
-					}
-					if qr.Canvas[r][c-1].data {
-						wb, _ := strconv.Atoi(string(qr.Interleaved[i]))
-						qr.Canvas[r][c-1].color = wb
-						i++
-					}
-				}
-			} else {
-				for r := 0; r < qr.Modules; r++ {
-					if qr.Canvas[r][c].data {
-						wb, _ := strconv.Atoi(string(qr.Interleaved[i]))
-						qr.Canvas[r][c].color = wb
-						i++
-					}
-					if qr.Canvas[r][c-1].data {
-						wb, _ := strconv.Atoi(string(qr.Interleaved[i]))
-						qr.Canvas[r][c-1].color = wb
-						i++
-					}
-				}
+				r = modules - 1 - i
+			}
+			if canvas[r][c].data {
+				fn(r, c)
+			}
+			if canvas[r][c-1].data {
+				fn(r, c-1)
 			}
-			up = !up
 		}
+		up = !up
+	}
+}
+
+func (qr *QR) drawDataBits() {
+	timing := 6
+	if qr.Type == TypeMicro {
+		timing = 0
+	} else if qr.Type == TypeRMQR {
+		timing = qr.Width - 1
 	}
+	i := 0
+	walkDataModules(qr.Canvas, timing, func(r, c int) {
+		wb, _ := strconv.Atoi(string(qr.Interleaved[i]))
+		qr.Canvas[r][c].color = wb
+		i++
+	})
 }
 
-func newCanvas(modules int) [][]*Cell {
-	canvas := make([][]*Cell, modules)
+// Creates a canvas of rows times cols modules, all of them data.
+func newCanvas(rows, cols int) [][]*Cell {
+	canvas := make([][]*Cell, rows)
 	for i, _ := range canvas {
-		canvas[i] = make([]*Cell, modules)
+		canvas[i] = make([]*Cell, cols)
 	}
 
-	for i := 0; i < modules; i++ {
-		for j := 0; j < modules; j++ {
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
 			canvas[i][j] = &Cell{0, true}
 		}
 	}
@@ -683,9 +942,8 @@ func newCanvas(modules int) [][]*Cell {
 }
 
 func copyCanvas(canvas, copy [][]*Cell) {
-	length := len(canvas)
-	for r := 0; r < length; r++ {
-		for c := 0; c < length; c++ {
+	for r := range canvas {
+		for c := range canvas[r] {
 			color := canvas[r][c].color
 			data := canvas[r][c].data
 			copy[r][c] = &Cell{color: color, data: data}
@@ -726,12 +984,11 @@ func mask7(row, col int) bool {
 }
 
 func maskCanvas(canvas [][]*Cell, fn mask) [][]*Cell {
-	length := len(canvas)
-	masked := newCanvas(length)
+	masked := newCanvas(len(canvas), len(canvas[0]))
 	copyCanvas(canvas, masked)
 
-	for r := 0; r < length; r++ {
-		for c := 0; c < length; c++ {
+	for r := range masked {
+		for c := range masked[r] {
 			if masked[r][c].data && fn(r, c) {
 				masked[r][c].color ^= 1
 			}
@@ -848,7 +1105,7 @@ func (qr *QR) dataMasking() {
 }
 
 func (qr *QR) drawFormatInformationString() {
-	fis := formatInformationStrings[qr.Mask]
+	fis := formatInformation(qr.Level, qr.Mask)
 	for i := 0; i <= 6; i++ {
 		num, _ := strconv.Atoi(string(fis[i]))
 		if i == 6 {
@@ -859,17 +1116,20 @@ func (qr *QR) drawFormatInformationString() {
 		qr.Canvas[qr.Modules-(i+1)][8].color = num
 	}
 
+	// The vertical run skips the timing pattern in row 6.
 	for i := 0; i <= 7; i++ {
 		num, _ := strconv.Atoi(string(fis[i+7]))
-		if i != 2 {
+		if i < 2 {
 			qr.Canvas[8-i][8].color = num
-			qr.Canvas[8][qr.Modules-(8-i)].color = num
+		} else {
+			qr.Canvas[7-i][8].color = num
 		}
+		qr.Canvas[8][qr.Modules-(8-i)].color = num
 	}
 }
 
 func (qr *QR) drawVersionInformationString() {
-	vis := versionInformationStrings[qr.Version-7]
+	vis := versionInformation(qr.Version)
 
 	x := 0
 	for i := 5; i >= 0; i-- {
@@ -883,25 +1143,48 @@ func (qr *QR) drawVersionInformationString() {
 }
 
 func (qr *QR) encoding() {
-	if qr.Mode == numeric {
-		qr.Encoding = terminator(indNumeric+indCount(qr.Length, qr.Mode, qr.Version)+
-			encNumeric(qr.Data), qr.Version)
-	} else if qr.Mode == alpha {
-		qr.Encoding = terminator(indAlpha+indCount(qr.Length, qr.Mode, qr.Version)+
-			encAlpha(qr.Data), qr.Version)
-	} else {
-		qr.Encoding = terminator(indBytes+indCount(qr.Length, qr.Mode, qr.Version)+
-			encBytes(qr.Data), qr.Version)
+	encoding := ""
+	if qr.Total != 0 {
+		encoding = appendHeader(qr.Sequence, qr.Total, qr.Parity)
+	}
+	if qr.ECI != 0 {
+		encoding += eciHeader(qr.ECI)
+	}
+	if qr.FNC1 != 0 {
+		encoding += fnc1Header(qr.FNC1, qr.Application)
+	}
+	for _, s := range qr.segments {
+		encoding += s.encode(qr.Version)
 	}
+	qr.Encoding = terminator(encoding, qr.Level, qr.Version)
 }
 
-func (qr *QR) interleave() {
+// Interleaves data and error correction codewords, followed by the
+// given number of remainder bits.
+func (qr *QR) interleave(remainder int) {
 	errorBytes := correction(qr.Encoding, qr.Errors, qr.Block1, qr.Block2, qr.Words1, qr.Words2)
 	interData := interleaveData(qr.Encoding, qr.Block1, qr.Block2, qr.Words1, qr.Words2)
 	interError := interleaveError(errorBytes, qr.Errors, qr.Block1, qr.Block2)
 
 	inter := byteArrayToEncoding(interData) + byteArrayToEncoding(interError)
-	qr.Interleaved = padRight(inter, len(inter)+blockInfo[qr.Version][6])
+	qr.Interleaved = padRight(inter, len(inter)+remainder)
+}
+
+// Draws all function patterns on a fresh canvas and reserves the
+// format and version information areas, so that only the modules
+// holding data bits remain marked as data.
+func (qr *QR) drawFunctionPatterns() {
+	qr.Canvas = newCanvas(qr.Modules, qr.Modules)
+	qr.placeFinderPatterns()
+	qr.placeSeparator()
+	qr.placeAlignmentPatterns()
+	qr.drawTimingPattern()
+	qr.drawDarkModule()
+	qr.reserveFormatInformationArea()
+
+	if qr.Version >= 7 {
+		qr.reserveVersionInformationData()
+	}
 }
 
 func upperLowerBorder(length int) string {
@@ -914,10 +1197,10 @@ func upperLowerBorder(length int) string {
 
 // Print QR-Code to terminal
 func (qr *QR) OutputTerminal() {
-	length := len(qr.Canvas)
+	length := len(qr.Canvas[0])
 	output := upperLowerBorder(length)
 
-	for i := 0; i < length; i++ {
+	for i := range qr.Canvas {
 		output += white
 		for j := 0; j < length; j++ {
 			if qr.Canvas[i][j].color == 0 {
@@ -931,44 +1214,97 @@ func (qr *QR) OutputTerminal() {
 	fmt.Println(output + upperLowerBorder(length))
 }
 
+// The modules of the symbol, true for dark ones.
+func (qr *QR) Matrix() [][]bool {
+	matrix := make([][]bool, len(qr.Canvas))
+	for r, row := range qr.Canvas {
+		matrix[r] = make([]bool, len(row))
+		for c, cell := range row {
+			matrix[r][c] = cell.color == 1
+		}
+	}
+	return matrix
+}
+
+// Encode data at the lowest error correction level L.
 func NewQR(data string) (*QR, error) {
-	length := len(data)
-	if length == 0 {
+	return NewQRLevel(data, LevelL)
+}
+
+// Encode data at the given error correction level. The smallest
+// version that holds the data at that level is chosen. Byte mode text
+// is written in ISO-8859-1 if possible and in UTF-8 behind an ECI
+// header otherwise.
+func NewQRLevel(data string, level int) (*QR, error) {
+	if len(data) == 0 {
 		return nil, errors.New("Empty data input.")
 	}
+	if level < LevelL || level > LevelH {
+		return nil, errors.New("Unknown error correction level.")
+	}
 
-	qr := QR{Data: data, Length: length}
-	qr.mode()
-	qr.version()
-	qr.Modules = ((qr.Version-1)*4 + 21)
+	qr := QR{Data: data, Level: level}
+	qr.version(qr.segmentText)
+	return qr.build()
+}
 
-	qr.Errors = blockInfo[qr.Version][1]
-	qr.Block1 = blockInfo[qr.Version][2]
-	qr.Words1 = blockInfo[qr.Version][3]
-	qr.Block2 = blockInfo[qr.Version][4]
-	qr.Words2 = blockInfo[qr.Version][5]
+// Splits text data into segments for the given version and chooses
+// the character set of byte mode, unless the ECI is fixed.
+func (qr *QR) segmentText(version int) []segment {
+	segments := segmentData(qr.Data, version, qr.FNC1 != 0)
+	switch {
+	case !qr.fixedECI:
+		qr.ECI = byteCharset(segments)
+	case qr.ECI != ECIUTF8:
+		latin1Segments(segments)
+	}
+	return segments
+}
 
-	qr.encoding()
-	qr.interleave()
+// Encode binary data such as compressed or CBOR encoded payloads at
+// the lowest error correction level L. The data is written as a single
+// byte mode segment, without interpreting it as text.
+func NewQRBytes(data []byte) (*QR, error) {
+	if len(data) == 0 {
+		return nil, errors.New("Empty data input.")
+	}
 
-	qr.Canvas = newCanvas(qr.Modules)
-	qr.placeFinderPatterns()
-	qr.placeSeparator()
-	qr.placeAlignmentPatterns()
-	qr.drawTimingPattern()
-	qr.drawDarkModule()
-	qr.reserveFormatInformationArea()
+	qr := QR{Data: string(data), Level: LevelL}
+	qr.version(func(int) []segment {
+		return []segment{{byteMode, qr.Data}}
+	})
+	return qr.build()
+}
 
-	if qr.Version >= 7 {
-		qr.reserveVersionInformationData()
+// Encodes the segments chosen by version and draws the symbol.
+func (qr *QR) build() (*QR, error) {
+	if qr.Version > versions {
+		return nil, errors.New("Data input too long.")
 	}
+	qr.Modules = ((qr.Version-1)*4 + 21)
+	qr.Width = qr.Modules
 
+	info := blockInfo[qr.Level][qr.Version]
+	qr.Errors = info[1]
+	qr.Block1 = info[2]
+	qr.Words1 = info[3]
+	qr.Block2 = info[4]
+	qr.Words2 = info[5]
+
+	qr.encoding()
+	qr.interleave(blockInfo[qr.Level][qr.Version][6])
+
+	qr.drawFunctionPatterns()
 	qr.drawDataBits()
-	qr.dataMasking()
+	if qr.fixedMask {
+		qr.Canvas = maskCanvas(qr.Canvas, masks[qr.Mask])
+	} else {
+		qr.dataMasking()
+	}
 	qr.drawFormatInformationString()
 
 	if qr.Version >= 7 {
 		qr.drawVersionInformationString()
 	}
-	return &qr, nil
+	return qr, nil
 }
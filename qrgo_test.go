@@ -1,6 +1,7 @@
 package qrgo
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -60,18 +61,18 @@ func TestByteArrayToEncoding(t *testing.T) {
 }
 
 func TestBinarySearch(t *testing.T) {
-	assert.Equal(t, 0, binarySearch(maxCharsNumeric, 20))
-	assert.Equal(t, 0, binarySearch(maxCharsNumeric, 41))
-	assert.Equal(t, 6, binarySearch(maxCharsNumeric, 360))
-	assert.Equal(t, 39, binarySearch(maxCharsNumeric, 7089))
-	assert.Equal(t, 0, binarySearch(maxCharsAlpha, 20))
-	assert.Equal(t, 0, binarySearch(maxCharsAlpha, 25))
-	assert.Equal(t, 6, binarySearch(maxCharsAlpha, 200))
-	assert.Equal(t, 39, binarySearch(maxCharsAlpha, 4296))
-	assert.Equal(t, 0, binarySearch(maxCharsBytes, 15))
-	assert.Equal(t, 0, binarySearch(maxCharsBytes, 17))
-	assert.Equal(t, 6, binarySearch(maxCharsBytes, 150))
-	assert.Equal(t, 39, binarySearch(maxCharsBytes, 2953))
+	assert.Equal(t, 0, binarySearch(maxCharsNumeric[LevelL], 20))
+	assert.Equal(t, 0, binarySearch(maxCharsNumeric[LevelL], 41))
+	assert.Equal(t, 6, binarySearch(maxCharsNumeric[LevelL], 360))
+	assert.Equal(t, 39, binarySearch(maxCharsNumeric[LevelL], 7089))
+	assert.Equal(t, 0, binarySearch(maxCharsAlpha[LevelL], 20))
+	assert.Equal(t, 0, binarySearch(maxCharsAlpha[LevelL], 25))
+	assert.Equal(t, 6, binarySearch(maxCharsAlpha[LevelL], 200))
+	assert.Equal(t, 39, binarySearch(maxCharsAlpha[LevelL], 4296))
+	assert.Equal(t, 0, binarySearch(maxCharsBytes[LevelL], 15))
+	assert.Equal(t, 0, binarySearch(maxCharsBytes[LevelL], 17))
+	assert.Equal(t, 6, binarySearch(maxCharsBytes[LevelL], 150))
+	assert.Equal(t, 39, binarySearch(maxCharsBytes[LevelL], 2953))
 }
 
 func TestCountIndicator(t *testing.T) {
@@ -84,15 +85,19 @@ func TestCountIndicator(t *testing.T) {
 	assert.Equal(t, "00000001", indCount(1, byteMode, 1))
 	assert.Equal(t, "0000000000000010", indCount(2, byteMode, 15))
 	assert.Equal(t, "0000000000000011", indCount(3, byteMode, 40))
+	assert.Equal(t, "00000001", indCount(1, kanji, 1))
+	assert.Equal(t, "0000000010", indCount(2, kanji, 15))
+	assert.Equal(t, "000000000011", indCount(3, kanji, 40))
 }
 
 func TestEncodingNumeric(t *testing.T) {
-	assert.Equal(t, "0", encNumeric("0"))
-	assert.Equal(t, "1", encNumeric("1"))
-	assert.Equal(t, "10", encNumeric("2"))
-	assert.Equal(t, "10", encNumeric("02"))
-	assert.Equal(t, "1010", encNumeric("10"))
-	assert.Equal(t, "1100100", encNumeric("100"))
+	assert.Equal(t, "0000", encNumeric("0"))
+	assert.Equal(t, "0001", encNumeric("1"))
+	assert.Equal(t, "0010", encNumeric("2"))
+	assert.Equal(t, "0000010", encNumeric("02"))
+	assert.Equal(t, "0001010", encNumeric("10"))
+	assert.Equal(t, "0001100100", encNumeric("100"))
+	assert.Equal(t, "00000000100000001", encNumeric("00201"))
 	// Doc example
 	assert.Equal(t, "110110001110000100101001", encNumeric("8675309"))
 }
@@ -113,6 +118,116 @@ func TestEncodingBytes(t *testing.T) {
 	assert.Equal(t, "0110000101100010", encBytes("ab"))
 	//Doc example
 	assert.Equal(t, "0100100001100101", encBytes("He"))
+	assert.Equal(t, "1100001110101001", encBytes("é"))
+	assert.Equal(t, "1111111100000000", encBytes("\xff\x00"))
+}
+
+func TestNewQRBytes(t *testing.T) {
+	data := make([]byte, 256)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	qr, err := NewQRBytes(data)
+	assert.Nil(t, err)
+	assert.Equal(t, byteMode, qr.Mode)
+	assert.Equal(t, 256, qr.Length)
+	assert.Equal(t, []segment{{byteMode, string(data)}}, qr.segments)
+
+	// Digits stay in byte mode as well.
+	qr, _ = NewQRBytes([]byte("12345678901234567890"))
+	assert.Equal(t, byteMode, qr.Mode)
+	assert.Equal(t, 2, qr.Version)
+
+	_, err = NewQRBytes(nil)
+	assert.NotNil(t, err)
+	_, err = NewQRBytes(make([]byte, 2954))
+	assert.NotNil(t, err)
+}
+
+func TestUTF8(t *testing.T) {
+	// Text within ISO-8859-1 needs no ECI.
+	qr, err := NewQR("Grüße")
+	assert.Nil(t, err)
+	assert.Equal(t, 5, qr.Length)
+	assert.Equal(t, 0, qr.ECI)
+	assert.Equal(t, indBytes+"00000101"+encBytes("Gr\xfc\xdfe"),
+		byteArrayToEncoding(qr.Encoding)[:4+8+40])
+
+	qr, err = NewQR("Grüße 😀")
+	assert.Nil(t, err)
+	assert.Equal(t, 12, qr.Length)
+	assert.Equal(t, ECIUTF8, qr.ECI)
+	assert.Equal(t, indECI+"00011010"+indBytes+"00001100"+encBytes("Grüße 😀"),
+		byteArrayToEncoding(qr.Encoding)[:12+4+8+96])
+}
+
+func TestEncodingKanji(t *testing.T) {
+	// Doc example
+	assert.Equal(t, "0110110011111", encKanji("点"))
+	assert.Equal(t, "1101010101010", encKanji("茗"))
+	assert.Equal(t, "0000000000000"+"0000000000001", encKanji("　、"))
+}
+
+func TestKanjiMode(t *testing.T) {
+	qr, err := NewQR("点茗漢字")
+	assert.Nil(t, err)
+	assert.Equal(t, kanji, qr.Mode)
+	assert.Equal(t, 4, qr.Length)
+	assert.Equal(t, 1, qr.Version)
+
+	// Latin letters have no double byte code in the Kanji mode.
+	qr, _ = NewQR("漢字 kanji")
+	assert.Equal(t, []segment{{kanji, "漢字"}, {byteMode, " kanji"}}, qr.segments)
+
+	qr, _ = NewQRLevel(strings.Repeat("漢", 11), LevelL)
+	assert.Equal(t, 2, qr.Version)
+}
+
+func TestTerminator(t *testing.T) {
+	// Four terminator bits spill over into the next byte.
+	enc := terminator(strings.Repeat("1", 14), LevelL, 1)
+	assert.Equal(t, 19, len(enc))
+	assert.Equal(t, []byte{255, 252, 0, 236, 17}, enc[:5])
+	// Less than four bits left.
+	enc = terminator(strings.Repeat("1", 150), LevelL, 1)
+	assert.Equal(t, []byte{255, 252}, enc[17:])
+}
+
+func TestLevels(t *testing.T) {
+	qr, err := NewQRLevel("HELLO WORLD", LevelM)
+	assert.Nil(t, err)
+	assert.Equal(t, LevelM, qr.Level)
+	assert.Equal(t, 1, qr.Version)
+	assert.Equal(t, []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17,
+		236, 17, 236, 17}, qr.Encoding)
+
+	qr, err = NewQRLevel("HELLO WORLD", LevelQ)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17,
+		236}, qr.Encoding)
+
+	qr, err = NewQRLevel("HELLO WORLD", LevelH)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, qr.Version)
+	assert.Equal(t, 28, qr.Errors)
+
+	_, err = NewQRLevel("HELLO WORLD", 4)
+	assert.NotNil(t, err)
+}
+
+func TestVersions(t *testing.T) {
+	for level := LevelL; level <= LevelH; level++ {
+		for version := 1; version <= versions; version++ {
+			data := strings.Repeat("a", maxCharsBytes[level][version-1])
+			qr, err := NewQRLevel(data, level)
+			assert.Nil(t, err)
+			assert.Equal(t, version, qr.Version)
+			assert.Equal(t, version*4+17, len(qr.Canvas))
+		}
+	}
+
+	_, err := NewQR(strings.Repeat("a", 2954))
+	assert.NotNil(t, err)
 }
 
 func TestMain(t *testing.T) {
@@ -0,0 +1,234 @@
+package qrgo
+
+import (
+	"errors"
+	"image"
+	"math"
+
+	"github.com/jeffallen/qrgo/binarizer"
+)
+
+const (
+	// Number of the most confirmed finder patterns grouped into
+	// triples when looking for a single symbol.
+	maxPatterns = 10
+
+	// Number of finder pattern triples tried before giving up.
+	maxAttempts = 5
+)
+
+// The average module size of the finder patterns. Module sizes
+// are measured along the image axes and are corrected for the
+// rotation of the symbol.
+func moduleSize(tl, tr, bl *finderPattern) float64 {
+	slant := math.Max(math.Abs(tr.x-tl.x), math.Abs(tr.y-tl.y)) / distance(tl, tr)
+	return (tl.module + tr.module + bl.module) / 3 * slant
+}
+
+// Estimates the number of modules per side from the distance
+// between the finder pattern centres, which lie 3.5 modules
+// inside the symbol, and rounds it to a valid 4 * V + 17.
+func estimateModules(tl, tr, bl *finderPattern) int {
+	dist := (distance(tl, tr) + distance(tl, bl)) / 2
+	modules := int(math.Floor(dist/moduleSize(tl, tr, bl)+0.5)) + 7
+	switch modules % 4 {
+	case 0:
+		modules++
+	case 2:
+		modules--
+	case 3:
+		modules += 2
+	}
+	return modules
+}
+
+// Fits the transform from module coordinates onto the image to the
+// three finder patterns and the bottom-right alignment pattern. The
+// alignment pattern is searched for in growing squares around the
+// position predicted by the finder patterns alone. Without it, the
+// fourth corner completes the parallelogram of the finder patterns.
+func locateSymbol(bits [][]bool, tl, tr, bl *finderPattern, modules int) perspective {
+	m := float64(modules)
+	from := [4][2]float64{{3.5, 3.5}, {m - 3.5, 3.5}, {m - 3.5, m - 3.5}, {3.5, m - 3.5}}
+	to := [4][2]float64{{tl.x, tl.y}, {tr.x, tr.y}, {tr.x + bl.x - tl.x, tr.y + bl.y - tl.y}, {bl.x, bl.y}}
+
+	positions := alignmentPositions((modules - 17) / 4)
+	if len(positions) == 0 {
+		return quadToQuad(from, to)
+	}
+
+	centre := float64(positions[len(positions)-1]) + 0.5
+	x, y := quadToQuad(from, to).transform(centre, centre)
+	module := moduleSize(tl, tr, bl)
+	for _, radius := range []float64{4, 8, 16} {
+		if ax, ay, ok := findAlignmentPattern(bits, x, y, module, radius*module); ok {
+			from[2] = [2]float64{centre, centre}
+			to[2] = [2]float64{ax, ay}
+			break
+		}
+	}
+	return quadToQuad(from, to)
+}
+
+// Samples the module centres of a symbol of the given size.
+//
+// Small symbols are sampled through the global transform found by
+// locateSymbol. From version 7
+// on, all alignment patterns are located near the positions predicted
+// by that transform and every cell of the grid they form is sampled
+// through its own transform, fitted to the four patterns at its
+// corners. This follows curved or unevenly skewed surfaces much better.
+// The outer cells extend to the border of the symbol and the corners
+// covered by finder patterns keep their predicted positions.
+func sampleGrid(bits [][]bool, global perspective, tl, tr, bl *finderPattern, modules int) [][]bool {
+	positions := alignmentPositions((modules - 17) / 4)
+
+	matrix := make([][]bool, modules)
+	for r := range matrix {
+		matrix[r] = make([]bool, modules)
+	}
+	if len(positions) < 3 {
+		for r := range matrix {
+			for c := range matrix[r] {
+				x, y := global.transform(float64(c)+0.5, float64(r)+0.5)
+				matrix[r][c] = pixel(bits, x, y)
+			}
+		}
+		return matrix
+	}
+
+	n, last := len(positions), len(positions)-1
+	module := moduleSize(tl, tr, bl)
+	grid := make([][][2]float64, n)
+	for i, row := range positions {
+		grid[i] = make([][2]float64, n)
+		for j, col := range positions {
+			x, y := global.transform(float64(col)+0.5, float64(row)+0.5)
+			if !(i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0) {
+				if ax, ay, ok := findAlignmentPattern(bits, x, y, module, 3*module); ok {
+					x, y = ax, ay
+				}
+			}
+			grid[i][j] = [2]float64{x, y}
+		}
+	}
+
+	// Index of the grid cell holding a row or column.
+	cell := func(k int) int {
+		i := 0
+		for i < n-2 && k >= positions[i+1] {
+			i++
+		}
+		return i
+	}
+	local := make([][]perspective, n-1)
+	for i := range local {
+		local[i] = make([]perspective, n-1)
+		for j := range local[i] {
+			r0, r1 := float64(positions[i])+0.5, float64(positions[i+1])+0.5
+			c0, c1 := float64(positions[j])+0.5, float64(positions[j+1])+0.5
+			local[i][j] = quadToQuad(
+				[4][2]float64{{c0, r0}, {c1, r0}, {c1, r1}, {c0, r1}},
+				[4][2]float64{grid[i][j], grid[i][j+1], grid[i+1][j+1], grid[i+1][j]})
+		}
+	}
+	for r := range matrix {
+		for c := range matrix[r] {
+			x, y := local[cell(r)][cell(c)].transform(float64(c)+0.5, float64(r)+0.5)
+			matrix[r][c] = pixel(bits, x, y)
+		}
+	}
+	return matrix
+}
+
+// The pixel containing the point, light outside the image.
+func pixel(bits [][]bool, x, y float64) bool {
+	px, py := int(math.Floor(x)), int(math.Floor(y))
+	if py < 0 || py >= len(bits) || px < 0 || px >= len(bits[py]) {
+		return false
+	}
+	return bits[py][px]
+}
+
+// Samples and decodes the symbol framed by the finder patterns.
+// Since the estimated size may be off by a version, the
+// neighbouring sizes are tried as well.
+func decodeAt(bits [][]bool, tl, tr, bl *finderPattern) (*Symbol, error) {
+	estimate := estimateModules(tl, tr, bl)
+	var err error
+	for _, modules := range []int{estimate, estimate + 4, estimate - 4} {
+		if modules < 21 || modules > 177 {
+			continue
+		}
+		global := locateSymbol(bits, tl, tr, bl, modules)
+		var res *Result
+		res, err = Decode(sampleGrid(bits, global, tl, tr, bl, modules))
+		if err == nil {
+			sym := &Symbol{Result: *res}
+			m := float64(modules)
+			for i, c := range [4][2]float64{{0, 0}, {m, 0}, {m, m}, {0, m}} {
+				sym.Corners[i][0], sym.Corners[i][1] = global.transform(c[0], c[1])
+			}
+			return sym, nil
+		}
+	}
+	if err == nil {
+		err = errors.New("Invalid symbol size.")
+	}
+	return nil, err
+}
+
+// The images tried in turn by the readers. The local threshold
+// copes with uneven lighting and comes first, followed by the
+// global one. Both are also tried inverted to read symbols
+// printed light on dark.
+func binarizations(img image.Image) [][][]bool {
+	local, global := binarizer.Local(img), binarizer.Global(img)
+	return [][][]bool{local, binarizer.Invert(local), global, binarizer.Invert(global)}
+}
+
+// ReadImage locates a symbol in a photo or scan and decodes it.
+//
+// The image is binarized and scanned for the three finder patterns
+// drawn by placeFinderPatterns. Their distance and the module size
+// estimated from them determine the version, after which the grid
+// of modules is sampled and handed to Decode. The alignment patterns
+// correct for perspective distortion of photos taken at an angle.
+//
+// Dark symbols on a light background are read as well as inverted
+// ones, and the image may be unevenly lit.
+func ReadImage(img image.Image) (*Result, error) {
+	if img.Bounds().Empty() {
+		return nil, errors.New("Empty image.")
+	}
+
+	var err error
+	for _, bits := range binarizations(img) {
+		var res *Result
+		if res, err = readSymbol(bits); err == nil {
+			return res, nil
+		}
+	}
+	return nil, err
+}
+
+// Locates and decodes a symbol in a binarized image.
+func readSymbol(bits [][]bool) (*Result, error) {
+	// False positives in the data region may form plausible
+	// triangles too, so the best few candidates are tried.
+	triples := finderTriples(findFinderPatterns(bits), maxPatterns)
+	if len(triples) == 0 {
+		return nil, errors.New("Finder patterns not found.")
+	}
+	if len(triples) > maxAttempts {
+		triples = triples[:maxAttempts]
+	}
+	var err error
+	for _, t := range triples {
+		var sym *Symbol
+		if sym, err = decodeAt(bits, t[0], t[1], t[2]); err == nil {
+			return &sym.Result, nil
+		}
+	}
+	return nil, err
+}
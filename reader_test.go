@@ -0,0 +1,183 @@
+package qrgo
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/jeffallen/qrgo/binarizer"
+	"github.com/stretchr/testify/assert"
+)
+
+// Renders the matrix into a white image of the given size. The
+// centre of every pixel is mapped to module coordinates by fn.
+func renderMapped(matrix [][]bool, size int, fn func(x, y float64) (float64, float64)) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	modules := float64(len(matrix))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			u, v := fn(float64(x)+0.5, float64(y)+0.5)
+			img.SetGray(x, y, color.Gray{255})
+			if u >= 0 && v >= 0 && u < modules && v < modules && matrix[int(v)][int(u)] {
+				img.SetGray(x, y, color.Gray{0})
+			}
+		}
+	}
+	return img
+}
+
+// Renders the matrix at scale pixels per module in the centre of
+// the image, rotated by angle radians.
+func renderSymbol(matrix [][]bool, scale, angle float64, size int) *image.Gray {
+	modules := float64(len(matrix))
+	centre := float64(size) / 2
+	sin, cos := math.Sin(angle), math.Cos(angle)
+	return renderMapped(matrix, size, func(x, y float64) (float64, float64) {
+		dx, dy := x-centre, y-centre
+		return (cos*dx+sin*dy)/scale + modules/2, (-sin*dx+cos*dy)/scale + modules/2
+	})
+}
+
+// Renders the matrix with its corners at the given image points.
+func renderPerspective(matrix [][]bool, corners [4][2]float64, size int) *image.Gray {
+	m := float64(len(matrix))
+	p := quadToQuad(corners, [4][2]float64{{0, 0}, {m, 0}, {m, m}, {0, m}})
+	return renderMapped(matrix, size, p.transform)
+}
+
+func TestEstimateModules(t *testing.T) {
+	tl := &finderPattern{x: 35, y: 35, module: 10}
+	tr := &finderPattern{x: 175, y: 35, module: 10}
+	bl := &finderPattern{x: 35, y: 175, module: 10}
+	assert.Equal(t, 21, estimateModules(tl, tr, bl))
+	tr.x, bl.y = 182, 182
+	assert.Equal(t, 21, estimateModules(tl, tr, bl))
+	tr.x, bl.y = 215, 215
+	assert.Equal(t, 25, estimateModules(tl, tr, bl))
+}
+
+func TestReadImage(t *testing.T) {
+	tests := []struct {
+		data  string
+		level int
+		scale float64
+		angle float64
+	}{
+		{"HELLO WORLD", LevelM, 4, 0},
+		{"EPFLLAUSANNE2016SWITZERLAND", LevelL, 3, 0.3},
+		{"https://example.com/labels/4711", LevelQ, 5, -1.1},
+		{strings.Repeat("PALLET 0042 ", 20), LevelL, 4, 0.6},
+		{strings.Repeat("manifest;", 60), LevelM, 3, 2.5},
+	}
+	for _, test := range tests {
+		qr, err := NewQRLevel(test.data, test.level)
+		assert.Nil(t, err)
+		size := int(float64(qr.Modules+8) * test.scale * 1.5)
+		img := renderSymbol(qr.Matrix(), test.scale, test.angle, size)
+
+		res, err := ReadImage(img)
+		if assert.Nil(t, err, test.data) {
+			assert.Equal(t, test.data, res.Data)
+			assert.Equal(t, qr.Version, res.Version)
+		}
+	}
+}
+
+func TestReadImagePerspective(t *testing.T) {
+	tests := []struct {
+		data    string
+		corners [4][2]float64
+	}{
+		{"SHEARED", [4][2]float64{{50, 40}, {330, 60}, {340, 330}, {60, 310}}},
+		{strings.Repeat("SKEWED BOX 17 ", 8), [4][2]float64{{30, 60}, {350, 20}, {370, 380}, {60, 330}}},
+		{strings.Repeat("https://example.com/manifest/0815 ", 12),
+			[4][2]float64{{20, 20}, {560, 80}, {520, 560}, {60, 500}}},
+	}
+	for _, test := range tests {
+		qr, _ := NewQRLevel(test.data, LevelM)
+		img := renderPerspective(qr.Matrix(), test.corners, 600)
+
+		res, err := ReadImage(img)
+		if assert.Nil(t, err, qr.Version) {
+			assert.Equal(t, test.data, res.Data)
+		}
+	}
+}
+
+func TestReadImageCurved(t *testing.T) {
+	// A large symbol wrapped around a cylinder seen from the front.
+	data := strings.Repeat("CURVED PARCEL LABEL ", 40)
+	qr, _ := NewQRLevel(data, LevelM)
+	modules := float64(qr.Modules)
+	size, scale, radius := 700, 5.0, 400.0
+	img := renderMapped(qr.Matrix(), size, func(x, y float64) (float64, float64) {
+		dx := (x - float64(size)/2) / radius
+		if dx <= -1 || dx >= 1 {
+			return -1, -1
+		}
+		u := math.Asin(dx) * radius / scale
+		return u + modules/2, (y-float64(size)/2)/scale + modules/2
+	})
+
+	res, err := ReadImage(img)
+	if assert.Nil(t, err, qr.Version) {
+		assert.Equal(t, data, res.Data)
+	}
+}
+
+func TestReadImageNoise(t *testing.T) {
+	qr, _ := NewQRLevel("WAREHOUSE 7 AISLE 12", LevelH)
+	img := renderSymbol(qr.Matrix(), 6, 0.2, 250)
+	r := rand.New(rand.NewSource(1))
+	for i := range img.Pix {
+		lum := int(img.Pix[i]) + r.Intn(81) - 40
+		img.Pix[i] = uint8(math.Max(0, math.Min(255, float64(lum))))
+	}
+
+	res, err := ReadImage(img)
+	if assert.Nil(t, err) {
+		assert.Equal(t, qr.Data, res.Data)
+	}
+}
+
+func TestReadImageLighting(t *testing.T) {
+	// A spotlight from the left leaves the right half of the
+	// symbol darker than the quiet zone on the left.
+	qr, _ := NewQRLevel("UNEVEN LIGHT IN AISLE 9", LevelM)
+	img := renderSymbol(qr.Matrix(), 6, 0.1, 240)
+	for y := 0; y < 240; y++ {
+		for x := 0; x < 240; x++ {
+			lum := float64(img.GrayAt(x, y).Y)
+			light := 1 - 0.8*float64(x)/240
+			img.SetGray(x, y, color.Gray{uint8(40 + lum*0.8*light)})
+		}
+	}
+	_, err := readSymbol(binarizer.Global(img))
+	assert.NotNil(t, err)
+
+	res, err := ReadImage(img)
+	if assert.Nil(t, err) {
+		assert.Equal(t, qr.Data, res.Data)
+	}
+}
+
+func TestReadImageInverted(t *testing.T) {
+	qr, _ := NewQR("LIGHT ON DARK")
+	img := renderSymbol(qr.Matrix(), 5, 0.4, 200)
+	for i := range img.Pix {
+		img.Pix[i] = 255 - img.Pix[i]
+	}
+
+	res, err := ReadImage(img)
+	if assert.Nil(t, err) {
+		assert.Equal(t, qr.Data, res.Data)
+	}
+}
+
+func TestReadImageBlank(t *testing.T) {
+	_, err := ReadImage(image.NewGray(image.Rect(0, 0, 100, 100)))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,295 @@
+package qrgo
+
+import (
+	"errors"
+	"strconv"
+)
+
+const rmqrECI = "111"
+
+var (
+	// Height and width of the sizes R7x43 to R17x139.
+	rmqrSizes = [][2]int{
+		{7, 43}, {7, 59}, {7, 77}, {7, 99}, {7, 139},
+		{9, 43}, {9, 59}, {9, 77}, {9, 99}, {9, 139},
+		{11, 27}, {11, 43}, {11, 59}, {11, 77}, {11, 99}, {11, 139},
+		{13, 27}, {13, 43}, {13, 59}, {13, 77}, {13, 99}, {13, 139},
+		{15, 43}, {15, 59}, {15, 77}, {15, 99}, {15, 139},
+		{17, 43}, {17, 59}, {17, 77}, {17, 99}, {17, 139},
+	}
+
+	// Columns of the alignment patterns and vertical timing patterns
+	// by width.
+	rmqrAlignment = map[int][]int{
+		27: {}, 43: {21}, 59: {19, 39}, 77: {25, 51}, 99: {23, 49, 75},
+		139: {27, 55, 83, 111},
+	}
+
+	// Bits of the count indicators for numeric, alpha, byte and kanji
+	// mode.
+	rmqrCountBits = [][4]int{
+		{4, 3, 3, 2}, {5, 5, 4, 3}, {6, 5, 5, 4}, {7, 6, 5, 5}, {7, 6, 6, 5},
+		{5, 5, 4, 3}, {6, 5, 5, 4}, {7, 6, 5, 5}, {7, 6, 6, 5}, {8, 7, 6, 6},
+		{4, 4, 3, 2}, {6, 5, 5, 4}, {7, 6, 5, 5}, {7, 6, 6, 5}, {8, 7, 6, 6}, {8, 7, 7, 6},
+		{5, 5, 4, 3}, {6, 6, 5, 5}, {7, 6, 6, 5}, {7, 7, 6, 6}, {8, 7, 7, 6}, {8, 8, 7, 7},
+		{7, 6, 6, 5}, {7, 7, 6, 5}, {8, 7, 7, 6}, {8, 7, 7, 6}, {9, 8, 7, 7},
+		{7, 6, 6, 5}, {8, 7, 6, 6}, {8, 7, 7, 6}, {8, 8, 7, 6}, {9, 8, 8, 7},
+	}
+
+	rmqrIndicators = map[int]string{numeric: "001", alpha: "010", byteMode: "011", kanji: "100"}
+
+	// Same columns as blockInfo. rMQR symbols only come at levels M
+	// and H.
+	rmqrBlockInfo = []map[int][7]int{
+		LevelL: {},
+		LevelM: {
+			1: {6, 7, 1, 6, 0, 0, 0}, 2: {12, 9, 1, 12, 0, 0, 3},
+			3: {20, 12, 1, 20, 0, 0, 5}, 4: {28, 16, 1, 28, 0, 0, 6},
+			5: {44, 24, 1, 44, 0, 0, 1}, 6: {12, 9, 1, 12, 0, 0, 2},
+			7: {21, 12, 1, 21, 0, 0, 3}, 8: {31, 9, 1, 15, 1, 16, 1},
+			9: {42, 24, 1, 42, 0, 0, 4}, 10: {63, 18, 1, 31, 1, 32, 5},
+			11: {7, 8, 1, 7, 0, 0, 2}, 12: {19, 12, 1, 19, 0, 0, 1},
+			13: {31, 16, 1, 31, 0, 0, 0}, 14: {43, 12, 1, 21, 1, 22, 2},
+			15: {57, 16, 1, 28, 1, 29, 7}, 16: {84, 16, 3, 28, 0, 0, 6},
+			17: {12, 9, 1, 12, 0, 0, 4}, 18: {27, 14, 1, 27, 0, 0, 1},
+			19: {38, 11, 2, 19, 0, 0, 6}, 20: {53, 16, 1, 26, 1, 27, 4},
+			21: {73, 20, 1, 36, 1, 37, 3}, 22: {106, 20, 2, 35, 1, 36, 0},
+			23: {31, 10, 1, 15, 1, 16, 1}, 24: {46, 14, 2, 23, 0, 0, 4},
+			25: {67, 18, 1, 33, 1, 34, 6}, 26: {88, 24, 2, 44, 0, 0, 7},
+			27: {127, 24, 2, 42, 1, 43, 2}, 28: {37, 12, 1, 18, 1, 19, 1},
+			29: {56, 16, 2, 28, 0, 0, 2}, 30: {78, 22, 2, 39, 0, 0, 0},
+			31: {100, 20, 2, 33, 1, 34, 3}, 32: {152, 20, 4, 38, 0, 0, 4},
+		},
+		LevelQ: {},
+		LevelH: {
+			1: {3, 10, 1, 3, 0, 0, 0}, 2: {7, 14, 1, 7, 0, 0, 3},
+			3: {10, 22, 1, 10, 0, 0, 5}, 4: {14, 30, 1, 14, 0, 0, 6},
+			5: {24, 22, 2, 12, 0, 0, 1}, 6: {7, 14, 1, 7, 0, 0, 2},
+			7: {11, 22, 1, 11, 0, 0, 3}, 8: {17, 16, 1, 8, 1, 9, 1},
+			9: {22, 22, 2, 11, 0, 0, 4}, 10: {33, 22, 3, 11, 0, 0, 5},
+			11: {5, 10, 1, 5, 0, 0, 2}, 12: {11, 20, 1, 11, 0, 0, 1},
+			13: {15, 16, 1, 7, 1, 8, 0}, 14: {23, 22, 1, 11, 1, 12, 2},
+			15: {29, 30, 1, 14, 1, 15, 7}, 16: {42, 30, 3, 14, 0, 0, 6},
+			17: {7, 14, 1, 7, 0, 0, 4}, 18: {13, 28, 1, 13, 0, 0, 1},
+			19: {20, 20, 2, 10, 0, 0, 6}, 20: {29, 28, 1, 14, 1, 15, 4},
+			21: {35, 26, 1, 11, 2, 12, 3}, 22: {54, 28, 2, 13, 2, 14, 0},
+			23: {17, 17, 1, 8, 1, 9, 1}, 24: {24, 25, 2, 12, 0, 0, 4},
+			25: {34, 23, 2, 11, 1, 12, 6}, 26: {48, 22, 4, 12, 0, 0, 7},
+			27: {69, 26, 1, 13, 4, 14, 2}, 28: {21, 20, 1, 10, 1, 11, 1},
+			29: {28, 30, 2, 14, 0, 0, 2}, 30: {38, 28, 1, 12, 2, 13, 0},
+			31: {56, 26, 4, 14, 0, 0, 3}, 32: {76, 26, 2, 12, 4, 13, 4},
+		},
+	}
+)
+
+// Number of bits of the count indicator of the mode in the given size.
+func rmqrCount(mode, version int) int {
+	index := map[int]int{numeric: 0, alpha: 1, byteMode: 2, kanji: 3}[mode]
+	return rmqrCountBits[version-1][index]
+}
+
+// Splits the data into the segments with the least number of bits in
+// the given size, behind three bits of mode indicator each.
+func rmqrSegments(data string, version int) []segment {
+	return splitSegments(data, segmentModes, func(mode int) int {
+		return 3 + rmqrCount(mode, version)
+	}, false)
+}
+
+// Total number of bits of the segments in the given size, including
+// the ECI header if any.
+func rmqrBits(segments []segment, eci, version int) int {
+	bits := 0
+	if eci != 0 {
+		bits = eciBits(eci) - len(indECI) + len(rmqrECI)
+	}
+	for _, s := range segments {
+		bits += 3 + rmqrCount(s.mode, version) + s.dataBits()
+	}
+	return bits
+}
+
+// Chooses the size with the fewest modules that holds the data at the
+// level and is at most height modules high. Sizes of equal area are
+// tried from the lowest height up.
+func (qr *QR) rmqrVersion(height int) {
+	area := 0
+	for v, size := range rmqrSizes {
+		if size[0] > height || area != 0 && size[0]*size[1] >= area {
+			continue
+		}
+		segments := rmqrSegments(qr.Data, v+1)
+		eci := byteCharset(segments)
+		if rmqrBits(segments, eci, v+1) <= rmqrBlockInfo[qr.Level][v+1][0]*8 {
+			qr.Version, qr.segments, qr.ECI = v+1, segments, eci
+			area = size[0] * size[1]
+		}
+	}
+	if qr.Version == 0 {
+		return
+	}
+	qr.Mode, qr.Length = qr.segments[0].mode, 0
+	for _, s := range qr.segments {
+		qr.Length += s.length()
+	}
+}
+
+// Mode indicators are three bits long and the terminator consists of
+// three zero bits. ECI designators follow their own mode indicator
+// just like in QR symbols.
+func (qr *QR) rmqrEncoding() {
+	encoding := ""
+	if qr.ECI != 0 {
+		encoding = rmqrECI + eciHeader(qr.ECI)[len(indECI):]
+	}
+	for _, s := range qr.segments {
+		encoding += rmqrIndicators[s.mode] +
+			padLeft(strconv.FormatInt(int64(s.length()), 2), rmqrCount(s.mode, qr.Version)) +
+			s.encodeData()
+	}
+	qr.Encoding = padCodewords(encoding, rmqrBlockInfo[qr.Level][qr.Version][0], 3)
+}
+
+// Positions of the bits of both copies of the format information,
+// least significant bit first. Next to the finder pattern they fill
+// three columns of five rows followed by three rows of a fourth
+// column. Above the finder sub pattern they fill three columns of
+// five rows followed by three columns of the top row.
+func rmqrFormatModules(height, width int) [2][18][2]int {
+	var pos [2][18][2]int
+	for n := 0; n < 15; n++ {
+		pos[0][n] = [2]int{1 + n%5, 8 + n/5}
+		pos[1][n] = [2]int{height - 6 + n%5, width - 8 + n/5}
+	}
+	for n := 15; n < 18; n++ {
+		pos[0][n] = [2]int{n - 14, 11}
+		pos[1][n] = [2]int{height - 6, width - 20 + n}
+	}
+	return pos
+}
+
+// rMQR symbols have a finder pattern on the left and a 5x5 finder sub
+// pattern in the bottom-right corner. Corner patterns mark the other
+// two corners. Alignment patterns sit on the top and bottom edges,
+// joined by vertical timing patterns, and timing patterns run along
+// all four edges.
+//
+//		R7x43, left and right end:
+//		11111110101010101 ... 1010101010101010111
+//		10000010000000000 ... 0000000000000000001
+//		10111010000000000 ... 0000000000000011111
+//		10111010000000000 ... 0000000000000010001
+//		10111010000000000 ... 0000000000000010101
+//		10000010000000000 ... 0000000000000010001
+//		11111110101010101 ... 1010101010101011111
+//
+func (qr *QR) drawRMQRFunctionPatterns() {
+	height, width := qr.Modules, qr.Width
+	qr.Canvas = newCanvas(height, width)
+	set := func(r, c, color int) {
+		qr.Canvas[r][c].color = color
+		qr.Canvas[r][c].data = false
+	}
+
+	drawPattern(qr.Canvas, 0, 0, 7)
+	for i := 0; i <= 7; i++ {
+		if i < height {
+			set(i, 7, 0)
+		}
+		if height > 7 {
+			set(7, i, 0)
+		}
+	}
+	drawPattern(qr.Canvas, height-5, width-5, 5)
+
+	corners := [][3]int{
+		{0, width - 2, 1}, {0, width - 1, 1}, {1, width - 2, 0}, {1, width - 1, 1},
+		{height - 1, 0, 1}, {height - 1, 1, 1}, {height - 1, 2, 1},
+	}
+	if height >= 11 {
+		corners = append(corners, [3]int{height - 2, 0, 1}, [3]int{height - 2, 1, 0})
+	}
+	for _, corner := range corners {
+		set(corner[0], corner[1], corner[2])
+	}
+
+	timing := map[int]bool{0: true, width - 1: true}
+	for _, col := range rmqrAlignment[width] {
+		drawPattern(qr.Canvas, 0, col-1, 3)
+		drawPattern(qr.Canvas, height-3, col-1, 3)
+		timing[col] = true
+	}
+	for r := 0; r < height; r++ {
+		for c := 0; c < width; c++ {
+			if !qr.Canvas[r][c].data {
+				continue
+			}
+			if r == 0 || r == height-1 {
+				set(r, c, (c+1)%2)
+			} else if timing[c] {
+				set(r, c, (r+1)%2)
+			}
+		}
+	}
+
+	for _, copy := range rmqrFormatModules(height, width) {
+		for _, p := range copy {
+			qr.Canvas[p[0]][p[1]].data = false
+		}
+	}
+}
+
+func (qr *QR) drawRMQRFormatInformation() {
+	fis := rmqrFormatInformation(qr.Level, qr.Version)
+	for i, copy := range rmqrFormatModules(qr.Modules, qr.Width) {
+		for n, p := range copy {
+			num, _ := strconv.Atoi(string(fis[i][17-n]))
+			qr.Canvas[p[0]][p[1]].color = num
+		}
+	}
+}
+
+// Encode data as an rMQR symbol at error correction level M or H.
+// The size with the fewest modules that holds the data is chosen and
+// stored as version 1 for R7x43 up to 32 for R17x139, with its height
+// in Modules and its width in Width. All rMQR symbols use mask 4.
+func NewRMQR(data string, level int) (*QR, error) {
+	return NewRMQRHeight(data, level, 17)
+}
+
+// Encode data as an rMQR symbol at most height modules high, e.g. 7
+// for the thinnest symbols.
+func NewRMQRHeight(data string, level, height int) (*QR, error) {
+	if len(data) == 0 {
+		return nil, errors.New("Empty data input.")
+	}
+	if level != LevelM && level != LevelH {
+		return nil, errors.New("Unknown error correction level for rMQR.")
+	}
+	if height < 7 {
+		return nil, errors.New("rMQR symbols are at least 7 modules high.")
+	}
+
+	qr := QR{Type: TypeRMQR, Data: data, Level: level}
+	qr.rmqrVersion(height)
+	if qr.Version == 0 {
+		return nil, errors.New("Data input too long for rMQR.")
+	}
+	qr.Modules, qr.Width = rmqrSizes[qr.Version-1][0], rmqrSizes[qr.Version-1][1]
+
+	info := rmqrBlockInfo[qr.Level][qr.Version]
+	qr.Errors = info[1]
+	qr.Block1 = info[2]
+	qr.Words1 = info[3]
+	qr.Block2 = info[4]
+	qr.Words2 = info[5]
+
+	qr.rmqrEncoding()
+	qr.interleave(info[6])
+
+	qr.drawRMQRFunctionPatterns()
+	qr.drawDataBits()
+	qr.Canvas, qr.Mask = maskCanvas(qr.Canvas, masks[4]), 4
+	qr.drawRMQRFormatInformation()
+	return &qr, nil
+}
@@ -0,0 +1,136 @@
+package qrgo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRMQRFormatInformation(t *testing.T) {
+	// Doc example
+	assert.Equal(t, [2]string{"101111100000011111", "010000100011010110"},
+		rmqrFormatInformation(LevelH, 17))
+	assert.Equal(t, [2]string{"011111101010110010", "100000101001111011"},
+		rmqrFormatInformation(LevelM, 1))
+}
+
+func TestRMQRLayout(t *testing.T) {
+	for level := LevelM; level <= LevelH; level += LevelH - LevelM {
+		for v, size := range rmqrSizes {
+			qr := QR{Type: TypeRMQR, Version: v + 1, Modules: size[0], Width: size[1]}
+			qr.drawRMQRFunctionPatterns()
+			modules := 0
+			walkDataModules(qr.Canvas, size[1]-1, func(r, c int) {
+				modules++
+			})
+
+			info := rmqrBlockInfo[level][v+1]
+			words := info[0] + info[1]*(info[2]+info[4])
+			assert.Equal(t, info[2]*info[3]+info[4]*info[5], info[0], size)
+			assert.Equal(t, words*8+info[6], modules, size)
+		}
+	}
+}
+
+func TestNewRMQR(t *testing.T) {
+	qr, err := NewRMQRHeight("RMQR", LevelM, 7)
+	assert.Nil(t, err)
+	assert.Equal(t, TypeRMQR, qr.Type)
+	assert.Equal(t, 1, qr.Version)
+	assert.Equal(t, 7, qr.Modules)
+	assert.Equal(t, 43, qr.Width)
+	assert.Equal(t, 4, qr.Mask)
+	assert.Equal(t, 6, len(qr.Encoding))
+	assert.Equal(t, 13*8, len(qr.Interleaved))
+
+	matrix := qr.Matrix()
+	assert.Equal(t, 7, len(matrix))
+	assert.Equal(t, 43, len(matrix[0]))
+	for c := 8; c < 41; c++ {
+		// Skip the alignment patterns.
+		if c >= 20 && c <= 22 {
+			continue
+		}
+		assert.Equal(t, c%2 == 0, matrix[0][c])
+		if c < 38 {
+			assert.Equal(t, c%2 == 0, matrix[6][c])
+		}
+	}
+	for r := 0; r < 7; r++ {
+		assert.Equal(t, r%2 == 0, matrix[r][21])
+		assert.False(t, matrix[r][7])
+	}
+	// Finder sub pattern
+	assert.True(t, matrix[4][40])
+	assert.False(t, matrix[3][40])
+	assert.True(t, matrix[2][38])
+
+	fis := rmqrFormatInformation(LevelM, 1)
+	for i, copy := range rmqrFormatModules(7, 43) {
+		for n, p := range copy {
+			assert.Equal(t, fis[i][17-n] == '1', matrix[p[0]][p[1]])
+		}
+	}
+	// Codewords 52 6A CA D0 EC 11 and 5B C0 CC E3 A4 58 97. The matrix
+	// below guards against regressions only: it was placed and masked
+	// from the layout described in rmqr.go, not taken from the example
+	// of ISO/IEC 23941 or from another encoder, and is to be replaced
+	// by such a reference. The placement starts in the second column
+	// from the right, going up, and ends in the leftmost one.
+	assert.Equal(t, []byte{0x52, 0x6a, 0xca, 0xd0, 0xec, 0x11}, qr.Encoding)
+	assert.Equal(t, []byte{0x5b, 0xc0, 0xcc, 0xe3, 0xa4, 0x58, 0x97},
+		encodingToByteArray(qr.Interleaved)[6:])
+	assert.Equal(t, parseMatrix(
+		"#######.#.#.#.#.#.#.###.#.#.#.#.#.#.#.#.###",
+		"#.....#..#.#.###.####.##.#..##.#...##...#.#",
+		"#.###.#.#.###.##..#####.##...#.###.########",
+		"#.###.#..##..#.###....#..#.####.#.#...#...#",
+		"#.###.#...####...#..###.#.#..#.##.##..#.#.#",
+		"#.....#.#####...#####.#.####.#.#...##.#...#",
+		"#######.#.#.#.#.#.#.###.#.#.#.#.#.#.#.#####",
+	), matrix)
+}
+
+func TestRMQRSizes(t *testing.T) {
+	tests := []struct {
+		data    string
+		level   int
+		height  int
+		version int
+	}{
+		{strings.Repeat("1", 12), LevelM, 7, 1},
+		{strings.Repeat("1", 12), LevelM, 17, 11},
+		{strings.Repeat("1", 14), LevelM, 17, 11},
+		{strings.Repeat("1", 15), LevelM, 17, 17},
+		{strings.Repeat("1", 13), LevelM, 7, 2},
+		{strings.Repeat("a", 40), LevelM, 9, 9},
+		{strings.Repeat("a", 40), LevelM, 17, 14},
+		{strings.Repeat("A", 219), LevelM, 17, 32},
+		{strings.Repeat("1", 361), LevelM, 17, 32},
+		{"rMQR ü", LevelH, 17, 17},
+		{"rMQR 😀", LevelH, 17, 18},
+	}
+	for _, test := range tests {
+		qr, err := NewRMQRHeight(test.data, test.level, test.height)
+		if assert.Nil(t, err, test.data) {
+			assert.Equal(t, test.version, qr.Version, test.data)
+			assert.Equal(t, rmqrSizes[test.version-1][0], len(qr.Canvas))
+			assert.Equal(t, rmqrSizes[test.version-1][1], len(qr.Canvas[0]))
+		}
+	}
+
+	qr, _ := NewRMQR("rMQR ü", LevelH)
+	assert.Equal(t, 0, qr.ECI)
+	qr, _ = NewRMQR("rMQR 😀", LevelH)
+	assert.Equal(t, ECIUTF8, qr.ECI)
+
+	_, err := NewRMQR(strings.Repeat("1", 362), LevelM)
+	assert.NotNil(t, err)
+	_, err = NewRMQRHeight(strings.Repeat("1", 103), LevelM, 7)
+	assert.NotNil(t, err)
+	_, err = NewRMQR("1", LevelL)
+	assert.NotNil(t, err)
+	_, err = NewRMQRHeight("1", LevelM, 5)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,219 @@
+package qrgo
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+// A run of the data string encoded in a single mode.
+type segment struct {
+	mode int
+	data string
+}
+
+// Modes the segmenter chooses from.
+var segmentModes = []int{numeric, alpha, byteMode, kanji}
+
+// The number of chars written to the count indicator. Byte mode
+// counts bytes, Kanji mode double byte chars.
+func (s segment) length() int {
+	if s.mode == byteMode {
+		return len(s.data)
+	}
+	return utf8.RuneCountInString(s.data)
+}
+
+// Number of bits the segment's chars take, without the mode and
+// count indicators.
+func (s segment) dataBits() int {
+	count := s.length()
+	switch s.mode {
+	case numeric:
+		return count/3*10 + []int{0, 4, 7}[count%3]
+	case alpha:
+		return count/2*11 + count%2*6
+	case kanji:
+		return count * 13
+	default:
+		return count * 8
+	}
+}
+
+// The mode indicator, count indicator and data bits of the segment.
+func (s segment) encode(version int) string {
+	ind := map[int]string{numeric: indNumeric, alpha: indAlpha, byteMode: indBytes, kanji: indKanji}
+	return ind[s.mode] + indCount(s.length(), s.mode, version) + s.encodeData()
+}
+
+// The data bits of the segment.
+func (s segment) encodeData() string {
+	switch s.mode {
+	case numeric:
+		return encNumeric(s.data)
+	case alpha:
+		return encAlpha(s.data)
+	case kanji:
+		return encKanji(s.data)
+	default:
+		return encBytes(s.data)
+	}
+}
+
+// Total number of bits of the segments in the given version.
+func segmentBits(segments []segment, version int) int {
+	bits := 0
+	for _, s := range segments {
+		bits += 4 + countBits(s.mode, version) + s.dataBits()
+	}
+	return bits
+}
+
+// Reports whether the char can be encoded in the given mode. With
+// FNC1, the alphanumeric mode encodes the GS separator as % and
+// literal ones as %%.
+func canEncode(mode int, c rune, fnc1 bool) bool {
+	switch mode {
+	case numeric:
+		return c >= '0' && c <= '9'
+	case alpha:
+		if fnc1 && c == gs {
+			return true
+		}
+		_, ok := alphaTable[c]
+		return ok
+	case kanji:
+		_, ok := toShiftJIS(c)
+		return ok
+	default:
+		return true
+	}
+}
+
+// Splits the data string into the segments with the least number of
+// bits for the given version, whose range determines the width of the
+// count indicators. With FNC1, the data of alphanumeric segments is
+// escaped by escapeFNC1.
+func segmentData(data string, version int, fnc1 bool) []segment {
+	return splitSegments(data, segmentModes, func(mode int) int {
+		return 4 + countBits(mode, version)
+	}, fnc1)
+}
+
+// Splits the data string into segments of the given modes with the
+// least number of bits, where header is the number of bits of the
+// mode and count indicators. Returns nil if a char can't be encoded
+// in any of the modes.
+//
+// A dynamic program walks the chars and keeps, for every mode, the
+// cheapest encoding of the data so far ending in that mode. Costs are
+// counted in sixths of a bit, so that numeric (10 bits per 3 chars)
+// and alphanumeric (11 bits per 2 chars) runs are charged exactly per
+// char, escaped ones twice. Byte mode chars of ISO-8859-1 take one
+// byte, others the bytes of their UTF-8 encoding. Switching modes
+// rounds up the running segment to whole bits and adds the mode and
+// count indicators of the next one.
+//
+//		ORDER 12345678901234567890 ref:abc:
+//			"ORDER " -> alpha
+//			"12345678901234567890" -> numeric
+//			" ref:abc" -> bytes
+//
+func splitSegments(data string, modes []int, header func(mode int) int, fnc1 bool) []segment {
+	// Chars are kept with their byte offsets, so that invalid UTF-8
+	// sequences pass through byte mode unchanged.
+	chars, offsets := []rune{}, []int{}
+	for i, c := range data {
+		chars, offsets = append(chars, c), append(offsets, i)
+	}
+	offsets = append(offsets, len(data))
+	if len(chars) == 0 {
+		return []segment{}
+	}
+
+	n := len(modes)
+	head := make([]int, n)
+	for m, mode := range modes {
+		head[m] = header(mode) * 6
+	}
+
+	// from[i][m] is the mode of char i if char i+1 is in mode m,
+	// or -1 if the char can't be encoded in mode m.
+	costs := append([]int{}, head...)
+	from := make([][]int, len(chars))
+	for i, c := range chars {
+		next := make([]int, n)
+		from[i] = make([]int, n)
+		for m, mode := range modes {
+			next[m], from[i][m] = math.MaxInt32, -1
+			if !canEncode(mode, c, fnc1) {
+				continue
+			}
+			// Escaped, a GS followed by a % would read as a
+			// literal % followed by a GS.
+			if fnc1 && mode == alpha && c == '%' && i > 0 && chars[i-1] == gs {
+				continue
+			}
+			var cost int
+			switch mode {
+			case numeric:
+				cost = 20
+			case alpha:
+				cost = 33
+				if fnc1 && c == '%' {
+					cost = 66
+				}
+			case kanji:
+				cost = 78
+			default:
+				cost = (offsets[i+1] - offsets[i]) * 8 * 6
+				if c <= 0xff && c != utf8.RuneError {
+					cost = 8 * 6
+				}
+			}
+			next[m], from[i][m] = costs[m]+cost, m
+		}
+
+		encodable := false
+		for m := range modes {
+			for k := range modes {
+				if from[i][k] < 0 {
+					continue
+				}
+				encodable = true
+				if cost := (next[k]+5)/6*6 + head[m]; cost < next[m] {
+					next[m], from[i][m] = cost, k
+				}
+			}
+		}
+		if !encodable {
+			return nil
+		}
+		costs = next
+	}
+
+	best := 0
+	for m := range modes {
+		if (costs[m]+5)/6 < (costs[best]+5)/6 {
+			best = m
+		}
+	}
+	chosen := make([]int, len(chars))
+	for i := len(chars) - 1; i >= 0; i-- {
+		best = from[i][best]
+		chosen[i] = modes[best]
+	}
+
+	segments := []segment{}
+	for i, start := 0, 0; i < len(chars); i++ {
+		if i+1 == len(chars) || chosen[i+1] != chosen[i] {
+			end := offsets[i+1]
+			s := segment{chosen[i], data[start:end]}
+			if fnc1 && s.mode == alpha {
+				s.data = escapeFNC1(s.data)
+			}
+			segments = append(segments, s)
+			start = end
+		}
+	}
+	return segments
+}
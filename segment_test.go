@@ -0,0 +1,79 @@
+package qrgo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmentData(t *testing.T) {
+	tests := []struct {
+		data     string
+		version  int
+		segments []segment
+	}{
+		{"", 1, []segment{}},
+		{"01234567", 1, []segment{{numeric, "01234567"}}},
+		{"HELLO WORLD", 1, []segment{{alpha, "HELLO WORLD"}}},
+		{"Hello", 1, []segment{{byteMode, "Hello"}}},
+		{"点茗", 1, []segment{{kanji, "点茗"}}},
+		// Doc example
+		{"ORDER 12345678901234567890 ref:abc", 1, []segment{
+			{alpha, "ORDER "}, {numeric, "12345678901234567890"}, {byteMode, " ref:abc"}}},
+		// A few digits are not worth a segment of their own.
+		{"a1b", 1, []segment{{byteMode, "a1b"}}},
+		{"ABC123", 1, []segment{{alpha, "ABC123"}}},
+		{"商品123456789", 1, []segment{{kanji, "商品"}, {numeric, "123456789"}}},
+		// Invalid UTF-8 stays as it is.
+		{"\xff\xfe12", 1, []segment{{byteMode, "\xff\xfe12"}}},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.segments, segmentData(test.data, test.version, false), test.data)
+	}
+
+	// With FNC1, separators are escaped in alphanumeric segments.
+	assert.Equal(t, []segment{{alpha, "10ABCDEF%"}, {numeric, "17201225"}},
+		segmentData("10ABCDEF\x1d17201225", 1, true))
+	assert.Equal(t, []segment{{alpha, "10AB%%C%"}, {numeric, "17201225"}},
+		segmentData("10AB%C\x1d17201225", 1, true))
+	// A % right after a separator can't be escaped.
+	assert.Equal(t, []segment{{byteMode, "AB\x1d%"}, {alpha, "CDEFGH"}},
+		segmentData("AB\x1d%CDEFGH", 1, true))
+}
+
+func TestSegmentBits(t *testing.T) {
+	segments := []segment{{alpha, "ORDER "}, {numeric, "12345678901234567890"}, {byteMode, " ref:abc"}}
+	assert.Equal(t, 4+9+33+4+10+67+4+8+64, segmentBits(segments, 1))
+	assert.Equal(t, 4+11+33+4+12+67+4+16+64, segmentBits(segments, 10))
+
+	// Never worse than a single mode.
+	for _, data := range []string{"ORDER 12345678901234567890 ref:abc", "a1b2c3d4", "ABC 123 def 456"} {
+		bits := segmentBits(segmentData(data, 1, false), 1)
+		assert.True(t, bits <= segmentBits([]segment{{byteMode, data}}, 1), data)
+	}
+}
+
+func TestMixedModes(t *testing.T) {
+	data := "ORDER 12345678901234567890 ref:abc"
+	qr, err := NewQRLevel(data, LevelM)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, qr.Version)
+	assert.Equal(t, alpha, qr.Mode)
+	assert.Equal(t, len(data), qr.Length)
+	assert.Equal(t, 3, len(qr.segments))
+
+	// All bytes would need version 3.
+	assert.True(t, len(data) > maxCharsBytes[LevelM][1])
+
+	res, err := Decode(qr.Matrix())
+	assert.Nil(t, err)
+	assert.Equal(t, data, res.Data)
+
+	// Long numeric runs switch the count indicator widths.
+	data = strings.Repeat("PART 4711-", 30) + strings.Repeat("0123456789", 40)
+	qr, _ = NewQR(data)
+	res, err = Decode(qr.Matrix())
+	assert.Nil(t, err)
+	assert.Equal(t, data, res.Data)
+}
@@ -0,0 +1,36 @@
+package qrgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShiftJIS(t *testing.T) {
+	tests := []struct {
+		char rune
+		code int
+	}{
+		{'　', 0x8140}, {'、', 0x8141}, {'〜', 0x8160}, {'ぁ', 0x829f},
+		{'ァ', 0x8340}, {'亜', 0x889f}, {'点', 0x935f}, {'茗', 0xe4aa},
+		{'熙', 0xeaa4},
+	}
+	for _, test := range tests {
+		code, ok := toShiftJIS(test.char)
+		assert.True(t, ok)
+		assert.Equal(t, test.code, code, string(test.char))
+
+		char, ok := fromShiftJIS(test.code)
+		assert.True(t, ok)
+		assert.Equal(t, test.char, char)
+	}
+
+	for _, c := range "Aa1 ~\\ｱ€" {
+		_, ok := toShiftJIS(c)
+		assert.False(t, ok, string(c))
+	}
+	for _, code := range []int{0x8040, 0x813f, 0x817f, 0x81fd, 0x8240, 0xa040, 0xeaa5, 0xeb40} {
+		_, ok := fromShiftJIS(code)
+		assert.False(t, ok, code)
+	}
+}
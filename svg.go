@@ -0,0 +1,190 @@
+package qrgo
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"hash/fnv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Options of WriteSVG. The zero value draws black modules on white in
+// a view box of one unit per module, scaling to the enclosing element.
+type SVGOptions struct {
+	// Width of the quiet zone in modules, as in ImageOptions.
+	QuietZone int
+
+	// Fill colours of the dark modules and the background, e.g.
+	// "#1a1a1a", "" for black and white. "none" leaves out the
+	// background.
+	Foreground string
+	Background string
+
+	// Size of a module in Unit, such as 0.5 with "mm". The width and
+	// height are left out for a size of 0.
+	ModuleSize float64
+	Unit       string
+
+	// Title announced by screen readers, left out if empty. TitleID
+	// is the id of its element, unique among SVGs inlined into one
+	// page. It defaults to one derived from the data and the title,
+	// so that equal ids label equal titles.
+	Title   string
+	TitleID string
+}
+
+// A corner of a module in view box coordinates.
+type point struct {
+	x, y int
+}
+
+// Traces the outlines of the dark regions of the canvas. The outlines
+// run clockwise around dark regions and counter-clockwise around light
+// holes, so that they fill correctly under the nonzero rule. Every
+// outline is a list of its corners, where the direction changes.
+func outlines(canvas [][]*Cell) [][]point {
+	dark := func(r, c int) bool {
+		return r >= 0 && r < len(canvas) && c >= 0 && c < len(canvas[r]) &&
+			canvas[r][c].color == 1
+	}
+
+	// The unit edges between dark and light modules, keyed by their
+	// start, with dark on the right hand side.
+	edges := map[point][]point{}
+	for r, row := range canvas {
+		for c := range row {
+			if !dark(r, c) {
+				continue
+			}
+			if !dark(r-1, c) {
+				edges[point{c, r}] = append(edges[point{c, r}], point{c + 1, r})
+			}
+			if !dark(r, c+1) {
+				edges[point{c + 1, r}] = append(edges[point{c + 1, r}], point{c + 1, r + 1})
+			}
+			if !dark(r+1, c) {
+				edges[point{c + 1, r + 1}] = append(edges[point{c + 1, r + 1}], point{c, r + 1})
+			}
+			if !dark(r, c-1) {
+				edges[point{c, r + 1}] = append(edges[point{c, r + 1}], point{c, r})
+			}
+		}
+	}
+
+	// Start points in reading order keep the output stable.
+	starts := make([]point, 0, len(edges))
+	for p := range edges {
+		starts = append(starts, p)
+	}
+	sort.Slice(starts, func(i, j int) bool {
+		return starts[i].y < starts[j].y || starts[i].y == starts[j].y && starts[i].x < starts[j].x
+	})
+
+	paths := [][]point{}
+	for _, start := range starts {
+		for len(edges[start]) > 0 {
+			path := []point{start}
+			for p := start; ; {
+				next := edges[p][0]
+				edges[p] = edges[p][1:]
+				if n := len(path); n >= 2 && collinear(path[n-2], path[n-1], next) {
+					path[n-1] = next
+				} else {
+					path = append(path, next)
+				}
+				if p = next; p == start {
+					break
+				}
+			}
+			// Drop the closing corner and merge across the start.
+			path = path[:len(path)-1]
+			if n := len(path); n >= 3 && collinear(path[n-1], path[0], path[1]) {
+				path = path[1:]
+			}
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// Reports whether b lies on the straight line from a to c.
+func collinear(a, b, c point) bool {
+	return a.x == b.x && b.x == c.x || a.y == b.y && b.y == c.y
+}
+
+// The path data of the outlines, moving absolutely to the first
+// corner of each outline and drawing relative lines from there.
+//
+//		2x1 dark modules at the origin:
+//			M0 0h2v1h-2z
+//
+func pathData(paths [][]point, offset int) string {
+	var b strings.Builder
+	for _, path := range paths {
+		b.WriteString("M" + strconv.Itoa(path[0].x+offset) + " " + strconv.Itoa(path[0].y+offset))
+		for i := 1; i < len(path); i++ {
+			dx, dy := path[i].x-path[i-1].x, path[i].y-path[i-1].y
+			if dy == 0 {
+				b.WriteString("h" + strconv.Itoa(dx))
+			} else {
+				b.WriteString("v" + strconv.Itoa(dy))
+			}
+		}
+		b.WriteString("z")
+	}
+	return b.String()
+}
+
+// Writes the symbol as an SVG image. All dark modules are drawn by a
+// single path tracing the outlines of the dark regions.
+func (qr *QR) WriteSVG(w io.Writer, opts SVGOptions) error {
+	if len(qr.Canvas) == 0 {
+		return errors.New("Symbol without modules.")
+	}
+	quiet := qr.quietZone(opts.QuietZone)
+	fg, bg := opts.Foreground, opts.Background
+	if fg == "" {
+		fg = "#000"
+	}
+	if bg == "" {
+		bg = "#fff"
+	}
+	width := len(qr.Canvas[0]) + 2*quiet
+	height := len(qr.Canvas) + 2*quiet
+	escape := func(s string) string {
+		var b strings.Builder
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+
+	out := bufio.NewWriter(w)
+	out.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 ` +
+		strconv.Itoa(width) + " " + strconv.Itoa(height) + `"`)
+	if opts.ModuleSize > 0 {
+		size := func(modules int) string {
+			return strconv.FormatFloat(float64(modules)*opts.ModuleSize, 'f', -1, 64) + escape(opts.Unit)
+		}
+		out.WriteString(` width="` + size(width) + `" height="` + size(height) + `"`)
+	}
+	if opts.Title != "" {
+		id := opts.TitleID
+		if id == "" {
+			h := fnv.New32a()
+			io.WriteString(h, qr.Data+"\x00"+opts.Title)
+			id = "qr-title-" + strconv.FormatUint(uint64(h.Sum32()), 16)
+		}
+		out.WriteString(` role="img" aria-labelledby="` + escape(id) + `"><title id="` +
+			escape(id) + `">` + escape(opts.Title) + "</title>\n")
+	} else {
+		out.WriteString(">\n")
+	}
+	if bg != "none" {
+		out.WriteString(`<rect width="100%" height="100%" fill="` + escape(bg) + `"/>` + "\n")
+	}
+	out.WriteString(`<path fill="` + escape(fg) + `" shape-rendering="crispEdges" d="` +
+		pathData(outlines(qr.Canvas), quiet) + `"/>` + "\n</svg>\n")
+	return out.Flush()
+}
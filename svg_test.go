@@ -0,0 +1,118 @@
+package qrgo
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Fills the outlines under the nonzero rule by the winding number at
+// the centre of every module.
+func fillOutlines(paths [][]point, rows, cols int) [][]bool {
+	filled := make([][]bool, rows)
+	for r := range filled {
+		filled[r] = make([]bool, cols)
+		for c := range filled[r] {
+			winding := 0
+			for _, path := range paths {
+				for i, a := range path {
+					b := path[(i+1)%len(path)]
+					if a.x != b.x || a.x <= c {
+						continue
+					}
+					if a.y <= r && b.y > r {
+						winding++
+					} else if b.y <= r && a.y > r {
+						winding--
+					}
+				}
+			}
+			filled[r][c] = winding != 0
+		}
+	}
+	return filled
+}
+
+func TestOutlines(t *testing.T) {
+	canvas := newCanvas(1, 2)
+	canvas[0][0].color, canvas[0][1].color = 1, 1
+	// Doc example
+	assert.Equal(t, "M0 0h2v1h-2z", pathData(outlines(canvas), 0))
+
+	// Diagonal neighbours and a ring with a hole.
+	canvas = newCanvas(4, 4)
+	for _, p := range [][2]int{{0, 0}, {1, 1}, {1, 2}, {1, 3}, {2, 1}, {2, 3}, {3, 1}, {3, 2}, {3, 3}} {
+		canvas[p[0]][p[1]].color = 1
+	}
+	paths := outlines(canvas)
+	assert.Equal(t, 3, len(paths))
+	assert.Equal(t, matrixOf(canvas), fillOutlines(paths, 4, 4))
+
+	qr, _ := NewQR("OUTLINES")
+	paths = outlines(qr.Canvas)
+	assert.Equal(t, qr.Matrix(), fillOutlines(paths, qr.Modules, qr.Modules))
+	corners := 0
+	for _, path := range paths {
+		corners += len(path)
+	}
+	dark := 0
+	for _, row := range qr.Matrix() {
+		for _, d := range row {
+			if d {
+				dark++
+			}
+		}
+	}
+	assert.True(t, corners < 4*dark/2)
+}
+
+func matrixOf(canvas [][]*Cell) [][]bool {
+	qr := QR{Canvas: canvas}
+	return qr.Matrix()
+}
+
+func TestWriteSVG(t *testing.T) {
+	qr, _ := NewRMQRHeight("SVG", LevelM, 7)
+	var buf bytes.Buffer
+	assert.Nil(t, qr.WriteSVG(&buf, SVGOptions{}))
+	svg := buf.String()
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 47 11">`))
+	assert.Contains(t, svg, `<rect width="100%" height="100%" fill="#fff"/>`)
+	assert.Contains(t, svg, `<path fill="#000" shape-rendering="crispEdges" d="M2 2h7v7h-7z`)
+	assert.Equal(t, 1, strings.Count(svg, "<path"))
+	assert.NotContains(t, svg, "<title")
+
+	buf.Reset()
+	assert.Nil(t, qr.WriteSVG(&buf, SVGOptions{QuietZone: -1, Foreground: "navy",
+		Background: "none", ModuleSize: 0.5, Unit: "mm", Title: "Cable <A&B>"}))
+	svg = buf.String()
+	assert.Contains(t, svg, `viewBox="0 0 43 7" width="21.5mm" height="3.5mm"`)
+	assert.Contains(t, svg, `fill="navy"`)
+	assert.Contains(t, svg, `d="M0 0h7v7h-7z`)
+	assert.NotContains(t, svg, "<rect")
+
+	// Symbols inlined into one page label their own titles.
+	id := regexp.MustCompile(`aria-labelledby="(qr-title-[0-9a-f]+)"`)
+	ids := []string{}
+	for _, data := range []string{"SVG 1", "SVG 2"} {
+		qr, _ := NewQR(data)
+		buf.Reset()
+		assert.Nil(t, qr.WriteSVG(&buf, SVGOptions{Title: "Cable"}))
+		match := id.FindStringSubmatch(buf.String())
+		if assert.NotNil(t, match) {
+			assert.Contains(t, buf.String(), `<title id="`+match[1]+`">Cable</title>`)
+			ids = append(ids, match[1])
+		}
+	}
+	assert.NotEqual(t, ids[0], ids[1])
+
+	buf.Reset()
+	assert.Nil(t, qr.WriteSVG(&buf, SVGOptions{Title: "Cable <A&B>", TitleID: "cable-1"}))
+	assert.Contains(t, buf.String(),
+		`role="img" aria-labelledby="cable-1"><title id="cable-1">Cable &lt;A&amp;B&gt;</title>`)
+
+	assert.NotNil(t, (&QR{}).WriteSVG(&buf, SVGOptions{}))
+}
@@ -0,0 +1,98 @@
+package qrgo
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Options of WriteHalfBlocks and WriteASCII.
+type TerminalOptions struct {
+	// Width of the quiet zone in modules, as in ImageOptions.
+	QuietZone int
+
+	// Draw the light modules and the quiet zone instead of the dark
+	// modules, for terminals printing light text on a dark background.
+	Invert bool
+}
+
+// The symbol with its quiet zone, true for the modules to draw.
+func (qr *QR) terminalMatrix(opts TerminalOptions) ([][]bool, error) {
+	if len(qr.Canvas) == 0 {
+		return nil, errors.New("Symbol without modules.")
+	}
+	quiet := qr.quietZone(opts.QuietZone)
+	rows, cols := len(qr.Canvas)+2*quiet, len(qr.Canvas[0])+2*quiet
+	matrix := make([][]bool, rows)
+	for r := range matrix {
+		matrix[r] = make([]bool, cols)
+		for c := range matrix[r] {
+			dark := false
+			if r >= quiet && r < rows-quiet && c >= quiet && c < cols-quiet {
+				dark = qr.Canvas[r-quiet][c-quiet].color == 1
+			}
+			matrix[r][c] = dark != opts.Invert
+		}
+	}
+	return matrix, nil
+}
+
+// Writes the symbol as Unicode half blocks, two rows of modules per
+// line and one column per module, which keeps a version 10 symbol with
+// its quiet zone within 65 columns.
+//
+//		Rows:  upper, lower
+//		Chars: ' '   no, no
+//		       '▀'   yes, no
+//		       '▄'   no, yes
+//		       '█'   yes, yes
+//
+func (qr *QR) WriteHalfBlocks(w io.Writer, opts TerminalOptions) error {
+	matrix, err := qr.terminalMatrix(opts)
+	if err != nil {
+		return err
+	}
+	out := bufio.NewWriter(w)
+	for r := 0; r < len(matrix); r += 2 {
+		var line strings.Builder
+		for c, upper := range matrix[r] {
+			lower := r+1 < len(matrix) && matrix[r+1][c]
+			switch {
+			case upper && lower:
+				line.WriteRune('█')
+			case upper:
+				line.WriteRune('▀')
+			case lower:
+				line.WriteRune('▄')
+			default:
+				line.WriteByte(' ')
+			}
+		}
+		out.WriteString(line.String() + "\n")
+	}
+	return out.Flush()
+}
+
+// Writes the symbol as plain ASCII without escape sequences, for logs
+// and terminals without Unicode. Every module is two chars wide to
+// keep it roughly square, "##" when drawn and "  " otherwise.
+func (qr *QR) WriteASCII(w io.Writer, opts TerminalOptions) error {
+	matrix, err := qr.terminalMatrix(opts)
+	if err != nil {
+		return err
+	}
+	out := bufio.NewWriter(w)
+	for _, row := range matrix {
+		var line strings.Builder
+		for _, draw := range row {
+			if draw {
+				line.WriteString("##")
+			} else {
+				line.WriteString("  ")
+			}
+		}
+		out.WriteString(line.String() + "\n")
+	}
+	return out.Flush()
+}
@@ -0,0 +1,65 @@
+package qrgo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteHalfBlocks(t *testing.T) {
+	qr, _ := NewQR("TERMINAL")
+	var buf bytes.Buffer
+	assert.Nil(t, qr.WriteHalfBlocks(&buf, TerminalOptions{QuietZone: -1}))
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, 11, len(lines))
+	for _, line := range lines {
+		assert.Equal(t, 21, utf8.RuneCountInString(line))
+	}
+	// Finder pattern over rows 0 to 6, separator in row 7
+	assert.True(t, strings.HasPrefix(lines[0], "█▀▀▀▀▀█"))
+	assert.True(t, strings.HasPrefix(lines[2], "█ ▀▀▀ █"))
+	assert.True(t, strings.HasPrefix(lines[3], "▀▀▀▀▀▀▀ "))
+
+	buf.Reset()
+	assert.Nil(t, qr.WriteHalfBlocks(&buf, TerminalOptions{Invert: true}))
+	lines = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, 15, len(lines))
+	assert.Equal(t, strings.Repeat("█", 29), lines[0])
+	assert.True(t, strings.HasPrefix(lines[2], "████ ▄▄▄▄▄ "))
+
+	version10, _ := NewQRWithOptions("TERMINAL", Options{Version: 10})
+	buf.Reset()
+	assert.Nil(t, version10.WriteHalfBlocks(&buf, TerminalOptions{}))
+	assert.Equal(t, 65, utf8.RuneCountInString(strings.Split(buf.String(), "\n")[0]))
+
+	assert.NotNil(t, (&QR{}).WriteHalfBlocks(&buf, TerminalOptions{}))
+}
+
+func TestWriteASCII(t *testing.T) {
+	qr, _ := NewQR("TERMINAL")
+	var buf bytes.Buffer
+	assert.Nil(t, qr.WriteASCII(&buf, TerminalOptions{QuietZone: 1}))
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, 23, len(lines))
+	assert.Equal(t, strings.Repeat(" ", 46), lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "  ##############  "))
+	assert.NotContains(t, buf.String(), "\033")
+
+	matrix := qr.Matrix()
+	for r, row := range matrix {
+		for c, dark := range row {
+			assert.Equal(t, dark, lines[r+1][2*c+2] == '#')
+		}
+	}
+
+	buf.Reset()
+	assert.Nil(t, qr.WriteASCII(&buf, TerminalOptions{QuietZone: 1, Invert: true}))
+	lines = strings.Split(buf.String(), "\n")
+	assert.Equal(t, strings.Repeat("#", 46), lines[0])
+	assert.True(t, strings.HasPrefix(lines[2], "##  ##########  ##"))
+
+	assert.NotNil(t, (&QR{}).WriteASCII(&buf, TerminalOptions{}))
+}